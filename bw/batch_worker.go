@@ -25,6 +25,11 @@ func (e *Error) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap 支持 errors.Is、errors.As
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
 // ErrorList ...
 type ErrorList []error
 
@@ -41,6 +46,11 @@ func (l ErrorList) Error() string {
 	return msg.String()
 }
 
+// Unwrap 与 errors.Join 的结果一样支持 errors.Is、errors.As 遍历所有错误
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
 // BatchWorker 批量工作
 type BatchWorker struct {
 	// 是否根据调用 Do 的顺序对错误进行排序, 默认 true
@@ -1,6 +1,7 @@
 package bw
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -48,3 +49,15 @@ func TestError(t *testing.T) {
 		t.Log("err:", err)
 	}
 }
+
+func TestErrorListUnwrap(t *testing.T) {
+	target := errors.New("target")
+	var err error = ErrorList{&Error{Index: 0, Err: errors.New("other")}, &Error{Index: 1, Err: target}}
+	if !errors.Is(err, target) {
+		t.Fatal("expect errors.Is")
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Index != 0 {
+		t.Fatalf("errors.As:%v", e)
+	}
+}
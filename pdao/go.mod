@@ -5,10 +5,12 @@ go 1.16
 require (
 	github.com/go-redis/redis/v8 v8.11.5
 	github.com/json-iterator/go v1.1.12
-	github.com/pan-jf/go-utils/plog v0.0.0-20220818073634-86d1262079e1
+	github.com/pan-jf/go-utils/plog v0.0.0-00010101000000-000000000000
 	github.com/stretchr/testify v1.8.0 // indirect
 	go.uber.org/zap v1.22.0
 	gorm.io/driver/mysql v1.3.6
 	gorm.io/gorm v1.23.8
 	gorm.io/plugin/dbresolver v1.2.2
 )
+
+replace github.com/pan-jf/go-utils/plog => ../plog
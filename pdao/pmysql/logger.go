@@ -0,0 +1,132 @@
+package pmysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+
+	"github.com/pan-jf/go-utils/plog"
+)
+
+// DefaultGormLoggerConfig 只输出慢查询和出错的SQL，慢查询为Warn，出错为Error，查不到记录不算错误。
+// LogLevel 改为 gormlogger.Info 时所有SQL以Debug级别输出，SQL中带有绑定的参数值，需要时再开启
+var DefaultGormLoggerConfig = gormlogger.Config{
+	SlowThreshold:             200 * time.Millisecond,
+	IgnoreRecordNotFoundError: true,
+	LogLevel:                  gormlogger.Warn,
+}
+
+// ParseGormLogLevel 解析配置中的gorm日志级别：silent、error、warn、info
+func ParseGormLogLevel(text string) (gormlogger.LogLevel, error) {
+	switch strings.ToLower(text) {
+	case "silent":
+		return gormlogger.Silent, nil
+	case "error":
+		return gormlogger.Error, nil
+	case "warn":
+		return gormlogger.Warn, nil
+	case "info":
+		return gormlogger.Info, nil
+	}
+	return 0, fmt.Errorf("unknown gorm log level:%s", text)
+}
+
+// GormLogger 使用plog输出的gorm日志，ctx中通过 plog.WithContext 附加的字段也会输出
+type GormLogger struct {
+	config gormlogger.Config
+}
+
+// NewGormLogger Colorful配置不生效
+func NewGormLogger(config gormlogger.Config) *GormLogger {
+	return &GormLogger{config: config}
+}
+
+// LogMode ...
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	config := l.config
+	config.LogLevel = level
+	return &GormLogger{config: config}
+}
+
+// Info ...
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if logger := l.logger(ctx, gormlogger.Info, zapcore.InfoLevel); logger != nil {
+		logger.Log(zapcore.InfoLevel, fmt.Sprintf(msg, data...), zap.String("source", utils.FileWithLineNum()))
+	}
+}
+
+// Warn ...
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if logger := l.logger(ctx, gormlogger.Warn, zapcore.WarnLevel); logger != nil {
+		logger.Log(zapcore.WarnLevel, fmt.Sprintf(msg, data...), zap.String("source", utils.FileWithLineNum()))
+	}
+}
+
+// Error ...
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if logger := l.logger(ctx, gormlogger.Error, zapcore.ErrorLevel); logger != nil {
+		logger.Log(zapcore.ErrorLevel, fmt.Sprintf(msg, data...), zap.String("source", utils.FileWithLineNum()))
+	}
+}
+
+// Trace 每条SQL执行后调用，输出SQL、影响行数和耗时
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.config.LogLevel <= gormlogger.Silent {
+		return
+	}
+
+	var (
+		elapsed = time.Since(begin)
+		lvl     zapcore.Level
+		msg     string
+	)
+	switch {
+	case err != nil && l.config.LogLevel >= gormlogger.Error &&
+		(!errors.Is(err, gorm.ErrRecordNotFound) || !l.config.IgnoreRecordNotFoundError):
+		lvl, msg = zapcore.ErrorLevel, "gorm error"
+	case l.config.SlowThreshold > 0 && elapsed > l.config.SlowThreshold && l.config.LogLevel >= gormlogger.Warn:
+		lvl, msg = zapcore.WarnLevel, "gorm slow query"
+	case l.config.LogLevel >= gormlogger.Info:
+		lvl, msg = zapcore.DebugLevel, "gorm query"
+	default:
+		return
+	}
+
+	logger := plog.Ctx(ctx)
+	if !logger.Enabled(lvl) {
+		return
+	}
+
+	sql, rows := fc()
+	fields := []zapcore.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("elapsed", elapsed),
+		zap.String("source", utils.FileWithLineNum()),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	logger.Log(lvl, msg, fields...)
+}
+
+// logger gorm和plog的级别都开启时返回ctx对应的Logger，否则返回nil。
+// utils.FileWithLineNum 需要在gorm直接调用的方法中调用，才能跳过正确的调用栈
+func (l *GormLogger) logger(ctx context.Context, level gormlogger.LogLevel, lvl zapcore.Level) *plog.Logger {
+	if l.config.LogLevel < level {
+		return nil
+	}
+	logger := plog.Ctx(ctx)
+	if !logger.Enabled(lvl) {
+		return nil
+	}
+	return logger
+}
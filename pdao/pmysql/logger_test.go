@@ -0,0 +1,62 @@
+package pmysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/pan-jf/go-utils/plog"
+)
+
+func TestGormLogger(t *testing.T) {
+	logs := plog.NewTestLogger(t)
+	logger := NewGormLogger(DefaultGormLoggerConfig).LogMode(gormlogger.Info)
+	ctx := context.Background()
+	fc := func() (string, int64) { return "SELECT * FROM `user`", 1 }
+
+	logger.Trace(ctx, time.Now(), fc, nil)
+	logger.Trace(ctx, time.Now().Add(-time.Second), fc, nil)
+	logger.Trace(ctx, time.Now(), fc, gorm.ErrInvalidData)
+	logger.Trace(ctx, time.Now(), fc, gorm.ErrRecordNotFound)
+	logger.LogMode(gormlogger.Silent).Trace(ctx, time.Now(), fc, gorm.ErrInvalidData)
+
+	entries := logs.AllUntimed()
+	levels := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.ErrorLevel, zapcore.DebugLevel}
+	if len(entries) != len(levels) {
+		t.Fatalf("entries:%v", entries)
+	}
+	for i, entry := range entries {
+		fields := entry.ContextMap()
+		if entry.Level != levels[i] || fields["sql"] != "SELECT * FROM `user`" || fields["rows"] != int64(1) ||
+			!strings.Contains(fields["source"].(string), "logger_test.go") {
+			t.Fatalf("entry:%d %+v fields:%v", i, entry, fields)
+		}
+	}
+}
+
+func TestGormLoggerDefault(t *testing.T) {
+	logs := plog.NewTestLogger(t)
+	logger := NewGormLogger(DefaultGormLoggerConfig)
+	ctx := context.Background()
+	fc := func() (string, int64) { return "SELECT * FROM `user` WHERE phone = '13800000000'", 1 }
+
+	// 默认不输出正常执行的SQL
+	logger.Trace(ctx, time.Now(), fc, nil)
+	logger.Trace(ctx, time.Now().Add(-time.Second), fc, nil)
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Level != zapcore.WarnLevel {
+		t.Fatalf("entries:%v", entries)
+	}
+
+	if lvl, err := ParseGormLogLevel("INFO"); err != nil || lvl != gormlogger.Info {
+		t.Fatalf("level:%v err:%v", lvl, err)
+	}
+	if _, err := ParseGormLogLevel("debug"); err == nil {
+		t.Fatal("expect level error")
+	}
+}
@@ -21,10 +21,27 @@ type DBConfig struct {
 	MaxIdleConnNum  int      `json:"maxIdleConnNum"`
 	MaxOpenConnNum  int      `json:"maxOpenConnNUm"`
 	ConnMaxLifeTime int      `json:"connMaxLifeTime"`
+	// SlowThreshold 慢查询阈值，单位毫秒，<=0 时使用 DefaultGormLoggerConfig 的配置
+	SlowThreshold int `json:"slowThreshold"`
+	// LogLevel gorm日志级别：silent、error、warn、info，为空时使用 DefaultGormLoggerConfig 的配置。
+	// info会以Debug级别输出所有SQL
+	LogLevel string `json:"logLevel"`
 }
 
 // InitMysql 初始化mysql
 func InitMysql(dbConfig *DBConfig) *gorm.DB {
+	loggerConfig := DefaultGormLoggerConfig
+	if dbConfig.SlowThreshold > 0 {
+		loggerConfig.SlowThreshold = time.Duration(dbConfig.SlowThreshold) * time.Millisecond
+	}
+	if dbConfig.LogLevel != "" {
+		var err error
+		if loggerConfig.LogLevel, err = ParseGormLogLevel(dbConfig.LogLevel); err != nil {
+			plog.Error("InitMysql log level", zap.String("logLevel", dbConfig.LogLevel), zap.Error(err))
+			return nil
+		}
+	}
+
 	db, err := sql.Open("mysql", dbConfig.Source)
 	if err != nil {
 		plog.Error("InitMysql sql open", zap.Any("dbConfig", dbConfig), zap.Error(err))
@@ -35,7 +52,7 @@ func InitMysql(dbConfig *DBConfig) *gorm.DB {
 	db.SetMaxOpenConns(dbConfig.MaxOpenConnNum)
 	db.SetConnMaxLifetime(time.Duration(dbConfig.ConnMaxLifeTime) * time.Minute)
 
-	gormDB, err := gorm.Open(mysql.New(mysql.Config{Conn: db}), &gorm.Config{})
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{Conn: db}), &gorm.Config{Logger: NewGormLogger(loggerConfig)})
 	if err != nil {
 		plog.Error("InitMysql gorm open", zap.Any("dbConfig", dbConfig), zap.Error(err))
 		return nil
@@ -2,7 +2,10 @@ package predis
 
 import (
 	"context"
+	"time"
+
 	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap/zapcore"
 )
 
 type RedisDb struct {
@@ -33,6 +36,38 @@ type RedisCfg struct {
 	Cluster  bool     `json:"cluster"`
 	Hosts    []string `json:"hosts"`
 	PoolSize int      `json:"poolSize"`
+	// SlowThreshold 慢命令阈值，单位毫秒，<=0 时使用 DefaultLogHookConfig 的配置
+	SlowThreshold int `json:"slowThreshold"`
+	// CmdLevel、SlowLevel、ErrorLevel 命令日志的级别，如debug、warn、off，为空时使用 DefaultLogHookConfig 的配置
+	CmdLevel   string `json:"cmdLevel"`
+	SlowLevel  string `json:"slowLevel"`
+	ErrorLevel string `json:"errorLevel"`
+}
+
+// logHook 根据配置生成命令日志的hook
+func (c *RedisCfg) logHook() (*LogHook, error) {
+	config := DefaultLogHookConfig
+	if c.SlowThreshold > 0 {
+		config.SlowThreshold = time.Duration(c.SlowThreshold) * time.Millisecond
+	}
+	for _, l := range []struct {
+		text  string
+		level *zapcore.Level
+	}{
+		{c.CmdLevel, &config.CmdLevel},
+		{c.SlowLevel, &config.SlowLevel},
+		{c.ErrorLevel, &config.ErrorLevel},
+	} {
+		if l.text == "" {
+			continue
+		}
+		lvl, err := ParseLevel(l.text)
+		if err != nil {
+			return nil, err
+		}
+		*l.level = lvl
+	}
+	return NewLogHook(config), nil
 }
 
 // Setup 初始化
@@ -56,10 +91,15 @@ func (r *RedisDb) SetupCluster(redisCfg *RedisCfg) error {
 	if redisCfg.PoolSize > 0 {
 		opt.PoolSize = redisCfg.PoolSize
 	}
+	hook, err := redisCfg.logHook()
+	if err != nil {
+		return err
+	}
 	redisClusterClient = redis.NewClusterClient(opt)
+	redisClusterClient.AddHook(hook)
 
 	//Ping
-	_, err := redisClusterClient.Ping(ctx).Result()
+	_, err = redisClusterClient.Ping(ctx).Result()
 	if err != nil {
 		return err
 	}
@@ -80,9 +120,14 @@ func (r *RedisDb) SetupClient(redisCfg *RedisCfg) error {
 	if redisCfg.PoolSize > 0 {
 		opt.PoolSize = redisCfg.PoolSize
 	}
+	hook, err := redisCfg.logHook()
+	if err != nil {
+		return err
+	}
 	client = redis.NewClient(opt)
+	client.AddHook(hook)
 	//Ping
-	_, err := client.Ping(ctx).Result()
+	_, err = client.Ping(ctx).Result()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,204 @@
+package predis
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog"
+)
+
+// OffLevel 低于Debug，plog的任何级别下都不会输出
+const OffLevel = zapcore.DebugLevel - 1
+
+// maxKeyLen 日志中key的最大长度，超出的部分截断
+const maxKeyLen = 128
+
+// LogHookConfig redis命令日志的配置
+type LogHookConfig struct {
+	// CmdLevel 正常执行的命令的日志级别，OffLevel 为不输出
+	CmdLevel zapcore.Level
+	// SlowLevel 耗时超过SlowThreshold的命令的日志级别，SlowThreshold<=0 时不区分慢命令
+	SlowLevel     zapcore.Level
+	SlowThreshold time.Duration
+	// ErrorLevel 执行出错的命令的日志级别，redis.Nil 不算错误
+	ErrorLevel zapcore.Level
+}
+
+// DefaultLogHookConfig 正常命令不输出，慢命令为Warn，出错为Error
+var DefaultLogHookConfig = LogHookConfig{
+	CmdLevel:      OffLevel,
+	SlowLevel:     zapcore.WarnLevel,
+	SlowThreshold: 100 * time.Millisecond,
+	ErrorLevel:    zapcore.ErrorLevel,
+}
+
+// predisDir 本包的目录，查找调用位置时跳过
+var predisDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file) + "/"
+}()
+
+type hookStartKey struct{}
+
+// LogHook 使用plog输出redis命令、耗时和错误的 redis.Hook，
+// ctx中通过 plog.WithContext 附加的字段也会输出
+type LogHook struct {
+	config LogHookConfig
+}
+
+// NewLogHook 通过 client.AddHook 使用
+func NewLogHook(config LogHookConfig) *LogHook {
+	return &LogHook{config: config}
+}
+
+// BeforeProcess ...
+func (h *LogHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, hookStartKey{}, time.Now()), nil
+}
+
+// AfterProcess ...
+func (h *LogHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	elapsed := hookElapsed(ctx)
+	err := cmdError(cmd)
+	lvl := h.level(elapsed, err)
+
+	logger := plog.Ctx(ctx)
+	if !logger.Enabled(lvl) {
+		return nil
+	}
+
+	fields := []zapcore.Field{
+		zap.String("cmd", cmdString(cmd)),
+		zap.Duration("elapsed", elapsed),
+		zap.String("source", source()),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	logger.Log(lvl, "redis command", fields...)
+	return nil
+}
+
+// BeforeProcessPipeline ...
+func (h *LogHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, hookStartKey{}, time.Now()), nil
+}
+
+// AfterProcessPipeline 整个pipeline输出一条日志，错误取第一个出错的命令
+func (h *LogHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	elapsed := hookElapsed(ctx)
+	var err error
+	for _, cmd := range cmds {
+		if err = cmdError(cmd); err != nil {
+			break
+		}
+	}
+	lvl := h.level(elapsed, err)
+
+	logger := plog.Ctx(ctx)
+	if !logger.Enabled(lvl) {
+		return nil
+	}
+
+	cmdStrings := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		cmdStrings = append(cmdStrings, cmdString(cmd))
+	}
+	fields := []zapcore.Field{
+		zap.Strings("cmds", cmdStrings),
+		zap.Duration("elapsed", elapsed),
+		zap.String("source", source()),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	logger.Log(lvl, "redis pipeline", fields...)
+	return nil
+}
+
+func (h *LogHook) level(elapsed time.Duration, err error) zapcore.Level {
+	switch {
+	case err != nil:
+		return h.config.ErrorLevel
+	case h.config.SlowThreshold > 0 && elapsed > h.config.SlowThreshold:
+		return h.config.SlowLevel
+	}
+	return h.config.CmdLevel
+}
+
+func hookElapsed(ctx context.Context) time.Duration {
+	if start, ok := ctx.Value(hookStartKey{}).(time.Time); ok {
+		return time.Since(start)
+	}
+	return 0
+}
+
+func cmdError(cmd redis.Cmder) error {
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		return err
+	}
+	return nil
+}
+
+// cmdString 只输出命令名和第一个参数（一般是key），不输出写入的值等其他参数，
+// 避免日志中出现业务数据。key超过 maxKeyLen 时截断
+func cmdString(cmd redis.Cmder) string {
+	args := cmd.Args()
+	if len(args) == 0 {
+		return ""
+	}
+	name := cmd.Name()
+	if len(args) == 1 {
+		return name
+	}
+
+	key := argString(args[1])
+	if len(key) > maxKeyLen {
+		key = key[:maxKeyLen] + "..."
+	}
+	return name + " " + key
+}
+
+func argString(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(arg)
+}
+
+// ParseLevel 解析配置中的级别，off为 OffLevel，其他与 zapcore.ParseLevel 相同
+func ParseLevel(text string) (zapcore.Level, error) {
+	if strings.EqualFold(text, "off") {
+		return OffLevel, nil
+	}
+	return zapcore.ParseLevel(text)
+}
+
+// source 跳过go-redis和本包，返回业务代码的调用位置
+func source() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.File, "/go-redis/redis/") &&
+			(!strings.HasPrefix(frame.File, predisDir) || strings.HasSuffix(frame.File, "_test.go")) {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package predis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog"
+)
+
+func TestLogHook(t *testing.T) {
+	logs := plog.NewTestLogger(t)
+	config := DefaultLogHookConfig
+	config.CmdLevel = zapcore.DebugLevel
+	hook := NewLogHook(config)
+	ctx := context.Background()
+
+	cmd := redis.NewStringCmd(ctx, "get", "key")
+	cmd.SetErr(redis.Nil)
+	hookCtx, _ := hook.BeforeProcess(ctx, cmd)
+	_ = hook.AfterProcess(hookCtx, cmd)
+
+	slow := redis.NewStatusCmd(ctx, "set", "key", []byte("v"))
+	_ = hook.AfterProcess(context.WithValue(ctx, hookStartKey{}, time.Now().Add(-time.Second)), slow)
+
+	failed := redis.NewIntCmd(ctx, "incr", "key")
+	failed.SetErr(errors.New("WRONGTYPE"))
+	hookCtx, _ = hook.BeforeProcessPipeline(ctx, []redis.Cmder{slow, failed})
+	_ = hook.AfterProcessPipeline(hookCtx, []redis.Cmder{slow, failed})
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("entries:%v", entries)
+	}
+	if fields := entries[0].ContextMap(); entries[0].Level != zapcore.DebugLevel || fields["cmd"] != "get key" ||
+		fields["error"] != nil || !strings.Contains(fields["source"].(string), "hook_test.go") {
+		t.Fatalf("entry:%+v fields:%v", entries[0], fields)
+	}
+	if fields := entries[1].ContextMap(); entries[1].Level != zapcore.WarnLevel || fields["cmd"] != "set key" {
+		t.Fatalf("entry:%+v fields:%v", entries[1], fields)
+	}
+	if fields := entries[2].ContextMap(); entries[2].Level != zapcore.ErrorLevel || fields["error"] != "WRONGTYPE" {
+		t.Fatalf("entry:%+v fields:%v", entries[2], fields)
+	}
+}
+
+func TestLogHookDefault(t *testing.T) {
+	logs := plog.NewTestLogger(t)
+	cfg := &RedisCfg{}
+	hook, err := cfg.logHook()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// 默认不输出正常执行的命令
+	_ = hook.AfterProcess(ctx, redis.NewStatusCmd(ctx, "set", "key", "secret"))
+	if logs.Len() != 0 {
+		t.Fatalf("entries:%v", logs.AllUntimed())
+	}
+
+	cfg = &RedisCfg{CmdLevel: "info", ErrorLevel: "off"}
+	if hook, err = cfg.logHook(); err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("k", maxKeyLen+10)
+	_ = hook.AfterProcess(ctx, redis.NewStatusCmd(ctx, "set", long, "secret"))
+	failed := redis.NewIntCmd(ctx, "incr", "key")
+	failed.SetErr(errors.New("WRONGTYPE"))
+	_ = hook.AfterProcess(ctx, failed)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Level != zapcore.InfoLevel ||
+		entries[0].ContextMap()["cmd"] != "set "+long[:maxKeyLen]+"..." {
+		t.Fatalf("entries:%v", entries)
+	}
+
+	if _, err = (&RedisCfg{SlowLevel: "verbose"}).logHook(); err == nil {
+		t.Fatal("expect level error")
+	}
+}
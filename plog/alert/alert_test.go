@@ -0,0 +1,124 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recorder) Notify(_ context.Context, alert *Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, *alert)
+	return nil
+}
+
+func (r *recorder) get() []Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Alert(nil), r.alerts...)
+}
+
+func TestDispatcherDedup(t *testing.T) {
+	r := &recorder{}
+	d := NewDispatcher(Config{Notifiers: []Notifier{r}, DedupWindow: 50 * time.Millisecond})
+	logger := zap.New(d.Core(), zap.AddCaller())
+
+	logger.Warn("warn")
+	for i := 0; i < 5; i++ {
+		logger.Error("db down", zap.Int("i", i))
+	}
+	time.Sleep(150 * time.Millisecond)
+	d.Close()
+
+	alerts := r.get()
+	if len(alerts) != 2 {
+		t.Fatalf("alerts:%+v", alerts)
+	}
+	if alerts[0].Message != "db down" || alerts[0].Repeated != 0 || alerts[0].Fields["i"] != int64(0) {
+		t.Fatalf("first:%+v", alerts[0])
+	}
+	if alerts[1].Repeated != 4 || alerts[1].Fields["i"] != int64(4) || alerts[1].Caller == "" {
+		t.Fatalf("summary:%+v", alerts[1])
+	}
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	r := &recorder{}
+	d := NewDispatcher(Config{Notifiers: []Notifier{r}, RateLimit: 3})
+	logger := zap.New(d.Core())
+
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		logger.Error(msg)
+	}
+	d.Close()
+
+	if len(r.get()) != 3 || d.Dropped() != 2 {
+		t.Fatalf("alerts:%d dropped:%d", len(r.get()), d.Dropped())
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(Config{Notifiers: []Notifier{NewWebhookNotifier(server.URL)}})
+	zap.New(d.Core()).Error("webhook", zap.String("k", "v"))
+	d.Close()
+
+	if got.Message != "webhook" || got.Level != "error" || got.Fields["k"] != "v" || d.Failed() != 0 {
+		t.Fatalf("alert:%+v failed:%d", got, d.Failed())
+	}
+
+	bad := NewWebhookNotifier("http://127.0.0.1:1")
+	if err := bad.Notify(context.Background(), &Alert{}); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestDispatcherCloseFlushesDedup(t *testing.T) {
+	r := &recorder{}
+	d := NewDispatcher(Config{Notifiers: []Notifier{r}, DedupWindow: time.Hour})
+	logger := zap.New(d.Core(), zap.AddCaller())
+	for i := 0; i < 3; i++ {
+		logger.Error("db down")
+	}
+	d.Close()
+
+	alerts := r.get()
+	if len(alerts) != 2 || alerts[1].Repeated != 2 {
+		t.Fatalf("alerts:%+v", alerts)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	n := &EmailNotifier{From: "plog@example.com", To: []string{"ops@example.com"}}
+	msg := string(n.message(&Alert{Level: "error", Message: "登录失败\r\nBcc: evil@example.com"}))
+
+	header := msg[:strings.Index(msg, "\r\n\r\n")]
+	for _, line := range strings.Split(header, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Fatalf("header injected:%q", header)
+		}
+	}
+	if !strings.Contains(header, "Subject: =?UTF-8?q?") {
+		t.Fatalf("subject not encoded:%q", header)
+	}
+}
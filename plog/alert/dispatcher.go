@@ -0,0 +1,298 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultDedupWindow  = time.Minute
+	defaultRateLimit    = 10
+	defaultRateInterval = time.Minute
+	defaultQueueSize    = 1024
+	defaultTimeout      = 5 * time.Second
+)
+
+// Config 告警配置，零值字段使用默认值
+type Config struct {
+	// MinLevel 触发告警的级别，为nil时默认Error及以上
+	MinLevel zapcore.LevelEnabler
+	// Notifiers 每条告警会发给所有的通知方式
+	Notifiers []Notifier
+	// DedupWindow 同一位置的同一消息在窗口内只告警一次，窗口结束时补发合并的条数，默认1分钟
+	DedupWindow time.Duration
+	// RateLimit 每个RateInterval内最多发送的告警条数，超出的丢弃，默认每分钟10条
+	RateLimit    int
+	RateInterval time.Duration
+	// QueueSize 等待发送的告警队列长度，默认1024
+	QueueSize int
+	// Timeout 单次通知的超时时间，默认5秒
+	Timeout time.Duration
+}
+
+type dedupKey struct {
+	message string
+	file    string
+	line    int
+}
+
+type dedupEntry struct {
+	windowEnd time.Time
+	repeated  int
+	last      *Alert
+}
+
+// Dispatcher 异步分发告警，可以并发使用
+type Dispatcher struct {
+	config Config
+
+	mu    sync.Mutex
+	dedup map[dedupKey]*dedupEntry
+	// 限流窗口
+	rateStart time.Time
+	rateCount int
+
+	queue   chan *Alert
+	dropped int64
+	failed  int64
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewDispatcher 创建后即启动后台发送协程，不再使用时调用 Close
+func NewDispatcher(config Config) *Dispatcher {
+	if config.MinLevel == nil {
+		config.MinLevel = zapcore.ErrorLevel
+	}
+	if config.DedupWindow <= 0 {
+		config.DedupWindow = defaultDedupWindow
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = defaultRateLimit
+	}
+	if config.RateInterval <= 0 {
+		config.RateInterval = defaultRateInterval
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	d := &Dispatcher{
+		config: config,
+		dedup:  make(map[dedupKey]*dedupEntry),
+		queue:  make(chan *Alert, config.QueueSize),
+		done:   make(chan struct{}),
+	}
+	d.wg.Add(1)
+	go d.loop()
+	return d
+}
+
+// Core 返回接入日志的core，只处理不低于MinLevel的日志
+func (d *Dispatcher) Core() zapcore.Core {
+	return &alertCore{
+		LevelEnabler: d.config.MinLevel,
+		dispatcher:   d,
+	}
+}
+
+// Close 停止后台协程，队列中尚未发送的告警及去重窗口内合并的告警会发送完
+func (d *Dispatcher) Close() {
+	d.closeOnce.Do(func() {
+		close(d.done)
+		d.wg.Wait()
+	})
+}
+
+// Dropped 因限流或队列满丢弃的告警条数
+func (d *Dispatcher) Dropped() int64 {
+	return atomic.LoadInt64(&d.dropped)
+}
+
+// Failed 发送失败的次数
+func (d *Dispatcher) Failed() int64 {
+	return atomic.LoadInt64(&d.failed)
+}
+
+// dispatch 去重后入队
+func (d *Dispatcher) dispatch(entry zapcore.Entry, alert *Alert) {
+	key := dedupKey{
+		message: entry.Message,
+		file:    entry.Caller.File,
+		line:    entry.Caller.Line,
+	}
+
+	d.mu.Lock()
+	if e, ok := d.dedup[key]; ok && entry.Time.Before(e.windowEnd) {
+		e.repeated++
+		e.last = alert
+		d.mu.Unlock()
+		return
+	}
+	d.dedup[key] = &dedupEntry{windowEnd: entry.Time.Add(d.config.DedupWindow)}
+	d.mu.Unlock()
+
+	d.enqueue(alert)
+}
+
+// flushDedup 窗口结束的记录如果有被合并的告警，补发最后一条并带上合并条数
+func (d *Dispatcher) flushDedup(now time.Time) {
+	for _, alert := range d.takeDedup(now, false) {
+		d.enqueue(alert)
+	}
+}
+
+// takeDedup 删除窗口已结束的记录，返回需要补发的告警，all为true时处理所有记录
+func (d *Dispatcher) takeDedup(now time.Time, all bool) []*Alert {
+	var alerts []*Alert
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, e := range d.dedup {
+		if !all && now.Before(e.windowEnd) {
+			continue
+		}
+		if e.repeated > 0 {
+			e.last.Repeated = e.repeated
+			alerts = append(alerts, e.last)
+		}
+		delete(d.dedup, key)
+	}
+	return alerts
+}
+
+func (d *Dispatcher) enqueue(alert *Alert) {
+	if !d.allow(alert.Time) {
+		atomic.AddInt64(&d.dropped, 1)
+		return
+	}
+	select {
+	case d.queue <- alert:
+	default:
+		atomic.AddInt64(&d.dropped, 1)
+	}
+}
+
+func (d *Dispatcher) allow(now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now.Sub(d.rateStart) >= d.config.RateInterval || now.Before(d.rateStart) {
+		d.rateStart = now
+		d.rateCount = 0
+	}
+	d.rateCount++
+	return d.rateCount <= d.config.RateLimit
+}
+
+func (d *Dispatcher) loop() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.config.DedupWindow / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case alert := <-d.queue:
+			d.notify(alert)
+		case now := <-ticker.C:
+			d.flushDedup(now)
+		case <-d.done:
+			for {
+				select {
+				case alert := <-d.queue:
+					d.notify(alert)
+				default:
+					// 补发还在去重窗口内的合并条数，仍然受限流控制
+					for _, alert := range d.takeDedup(time.Now(), true) {
+						if d.allow(alert.Time) {
+							d.notify(alert)
+						} else {
+							atomic.AddInt64(&d.dropped, 1)
+						}
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) notify(alert *Alert) {
+	for _, n := range d.config.Notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+		if err := n.Notify(ctx, alert); err != nil {
+			atomic.AddInt64(&d.failed, 1)
+			// 告警失败不能再走日志，避免循环
+			fmt.Fprintf(os.Stderr, "plog alert notify failed, msg:%s, err:%v\n", alert.Message, err)
+		}
+		cancel()
+	}
+}
+
+type alertCore struct {
+	zapcore.LevelEnabler
+	fields     []zapcore.Field
+	dispatcher *Dispatcher
+}
+
+// With ...
+func (c *alertCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &alertCore{
+		LevelEnabler: c.LevelEnabler,
+		fields:       merged,
+		dispatcher:   c.dispatcher,
+	}
+}
+
+// Check ...
+func (c *alertCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write ...
+func (c *alertCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	alert := &Alert{
+		Level:      entry.Level.String(),
+		Time:       entry.Time,
+		LoggerName: entry.LoggerName,
+		Message:    entry.Message,
+		Fields:     enc.Fields,
+	}
+	if entry.Caller.Defined {
+		alert.Caller = entry.Caller.TrimmedPath()
+	}
+
+	c.dispatcher.dispatch(entry, alert)
+	return nil
+}
+
+// Sync ...
+func (c *alertCore) Sync() error {
+	return nil
+}
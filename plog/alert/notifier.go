@@ -0,0 +1,164 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Alert 一条告警
+type Alert struct {
+	Level      string                 `json:"level"`
+	Time       time.Time              `json:"time"`
+	LoggerName string                 `json:"logger,omitempty"`
+	Message    string                 `json:"msg"`
+	Caller     string                 `json:"caller,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	// Repeated 去重窗口内被合并的相同告警条数
+	Repeated int `json:"repeated,omitempty"`
+}
+
+// Notifier 告警通知方式
+type Notifier interface {
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+// NotifierFunc 自定义的通知函数
+type NotifierFunc func(ctx context.Context, alert *Alert) error
+
+// Notify ...
+func (f NotifierFunc) Notify(ctx context.Context, alert *Alert) error {
+	return f(ctx, alert)
+}
+
+// 读取webhook响应的最大长度，超出时不再复用连接
+const maxResponseSize = 64 * 1024
+
+// WebhookNotifier 以json格式POST到指定地址
+type WebhookNotifier struct {
+	URL    string
+	Header http.Header
+	Client *http.Client
+}
+
+// NewWebhookNotifier ...
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url}
+}
+
+// Notify ...
+func (n *WebhookNotifier) Notify(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "marshal alert")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "new webhook request")
+	}
+	req = req.WithContext(ctx)
+	for k, v := range n.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "post webhook")
+	}
+	// 读完响应才能复用连接
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook response status:%d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EmailNotifier 通过SMTP发送邮件
+type EmailNotifier struct {
+	// Addr smtp服务地址，如 smtp.example.com:25
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Notify ...
+func (n *EmailNotifier) Notify(_ context.Context, alert *Alert) error {
+	var auth smtp.Auth
+	if n.Username != "" {
+		host := n.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+
+	return errors.Wrap(smtp.SendMail(n.Addr, auth, n.From, n.To, n.message(alert)), "send alert email")
+}
+
+func (n *EmailNotifier) message(alert *Alert) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue(n.From) + "\r\n")
+	b.WriteString("To: " + headerValue(strings.Join(n.To, ",")) + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", headerValue(Subject(alert))) + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(Text(alert))
+	return []byte(b.String())
+}
+
+// headerValue 去掉换行，避免日志消息中的内容注入邮件头
+func headerValue(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+// Subject 告警的标题
+func Subject(alert *Alert) string {
+	return fmt.Sprintf("[%s] %s", strings.ToUpper(alert.Level), alert.Message)
+}
+
+// Text 告警的文本内容，字段按key排序
+func Text(alert *Alert) string {
+	var b strings.Builder
+	b.WriteString("time: " + alert.Time.Format("2006-01-02 15:04:05") + "\n")
+	b.WriteString("level: " + alert.Level + "\n")
+	if alert.LoggerName != "" {
+		b.WriteString("logger: " + alert.LoggerName + "\n")
+	}
+	if alert.Caller != "" {
+		b.WriteString("caller: " + alert.Caller + "\n")
+	}
+	b.WriteString("msg: " + alert.Message + "\n")
+	if alert.Repeated > 0 {
+		b.WriteString(fmt.Sprintf("repeated: %d\n", alert.Repeated))
+	}
+
+	keys := make([]string, 0, len(alert.Fields))
+	for k := range alert.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(fmt.Sprintf("%s: %v\n", k, alert.Fields[k]))
+	}
+	return b.String()
+}
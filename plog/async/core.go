@@ -18,7 +18,16 @@ func NewAsyncFileCore(
 	zapLevelEnabler zapcore.LevelEnabler,
 	encoder zapcore.Encoder,
 	fileName string) (*FileCore, error) {
-	asyncLogger, err := NewAsyncWriteLogger(fileName)
+	return NewAsyncFileCoreWithPolicy(zapLevelEnabler, encoder, fileName, DefaultRotatePolicy)
+}
+
+// NewAsyncFileCoreWithPolicy 按指定的切割策略创建
+func NewAsyncFileCoreWithPolicy(
+	zapLevelEnabler zapcore.LevelEnabler,
+	encoder zapcore.Encoder,
+	fileName string,
+	policy RotatePolicy) (*FileCore, error) {
+	asyncLogger, err := NewAsyncWriteLoggerWithPolicy(fileName, policy)
 	if err != nil {
 		return nil, err
 	}
@@ -54,15 +63,22 @@ func (c *FileCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
 		return errors.Wrap(err, "failed to encode log entry")
 	}
 
-	msg := buffer.String()
-	err = c.asyncLogger.WriteString(msg)
+	_, err = c.asyncLogger.Write(buffer.Bytes())
 	buffer.Free()
 	return err
 
 }
 
-// Sync ...
+// Sync 只刷新，不关闭文件
 func (c *FileCore) Sync() error {
+	if c.asyncLogger != nil {
+		return c.asyncLogger.Sync()
+	}
+	return nil
+}
+
+// Close 关闭日志文件，With 派生的core共用同一个文件，关闭后都不能再写入
+func (c *FileCore) Close() error {
 	if c.asyncLogger != nil {
 		return c.asyncLogger.Close()
 	}
@@ -0,0 +1,92 @@
+package async
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFileCoreSyncAndClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plog_core")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "app.log")
+	core, err := NewAsyncFileCore(zapcore.DebugLevel, zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := zap.New(core)
+
+	logger.Info("before sync")
+	if err = logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after sync")
+
+	if err = core.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = core.Write(zapcore.Entry{Message: "after close"}, nil); err != ErrClosed {
+		t.Fatalf("write after close:%v", err)
+	}
+	_ = core.Close()
+
+	data, _ := ioutil.ReadFile(fileName)
+	if !strings.Contains(string(data), "before sync") || !strings.Contains(string(data), "after sync") ||
+		strings.Contains(string(data), "after close") {
+		t.Fatalf("content:%s", data)
+	}
+}
+
+// syncCounter 记录Sync调用次数，其他方法交给真实的文件写入
+type syncCounter struct {
+	fileWriter
+	syncs int
+}
+
+func (w *syncCounter) Sync() error {
+	w.syncs++
+	return w.fileWriter.Sync()
+}
+
+func TestFileCoreSyncFile(t *testing.T) {
+	for _, policy := range []RotatePolicy{DefaultRotatePolicy, {External: true}} {
+		fileName := filepath.Join(t.TempDir(), "app.log")
+		core, err := NewAsyncFileCoreWithPolicy(zapcore.DebugLevel, zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), fileName, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counter := &syncCounter{fileWriter: core.asyncLogger.writer}
+		core.asyncLogger.writer = counter
+		logger := zap.New(core).With(zap.String("k", "v"))
+
+		// 还没有打开文件时不报错
+		if err = logger.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("synced")
+		if err = logger.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		if counter.syncs != 2 {
+			t.Fatalf("external:%v syncs:%d", policy.External, counter.syncs)
+		}
+		data, _ := ioutil.ReadFile(fileName)
+		if !strings.Contains(string(data), "synced") {
+			t.Fatalf("content:%s", data)
+		}
+
+		_ = core.Close()
+		if err = core.Sync(); err != nil || counter.syncs != 2 {
+			t.Fatalf("sync after close:%v syncs:%d", err, counter.syncs)
+		}
+	}
+}
@@ -1,101 +1,86 @@
 package async
 
 import (
-	"context"
 	"sync"
+	"sync/atomic"
 
-	"gopkg.in/natefinch/lumberjack.v2"
+	"github.com/pkg/errors"
 )
 
 const (
-	// 缓存切片容量
-	maxChanSize = 256 * 1024
 	maxFileSize = 4 * 1024 // 4GBytes
 	maxBackups  = 10
 	maxAge      = 7
 )
 
-// WriterLogger 异步写日志
+// ErrClosed 关闭后继续写入时返回
+var ErrClosed = errors.New("plog: writer closed")
+
+// fileWriter 日志文件的写入方式，自己切割或由外部切割
+type fileWriter interface {
+	Write(p []byte) (int, error)
+	Sync() error
+	Rotate() error
+	Close() error
+}
+
+// WriterLogger 写日志文件，Write返回时已经写入文件，没有自己的缓冲和后台协程。
+// 包名沿用之前的async，落盘由操作系统负责，需要时调用 Sync
 type WriterLogger struct {
-	writer  *lumberjack.Logger
-	closed  bool
-	msgChan chan string
-	ctx     context.Context
-	cancel  context.CancelFunc
-	wg      sync.WaitGroup
+	writer    fileWriter
+	closed    int32
+	closeOnce sync.Once
 }
 
-// NewAsyncWriteLogger 对外接口
+// NewAsyncWriteLogger 对外接口，使用默认切割策略
 func NewAsyncWriteLogger(filename string) (*WriterLogger, error) {
-	l := WriterLogger{
-		writer: &lumberjack.Logger{
-			Filename:   filename,
-			MaxSize:    maxFileSize,
-			MaxBackups: maxBackups,
-			MaxAge:     maxAge,
-			Compress:   false, // 是否压缩日志
-			LocalTime:  true,
-		},
-		msgChan: make(chan string, maxChanSize),
-	}
+	return NewAsyncWriteLoggerWithPolicy(filename, DefaultRotatePolicy)
+}
 
-	l.ctx, l.cancel = context.WithCancel(context.Background())
-	l.wg.Add(1)
-	go func(lg *WriterLogger) {
-		for {
-			if logLoop(lg) {
-				break
-			}
-		}
-		lg.wg.Done()
-	}(&l)
-	return &l, nil
+// NewAsyncWriteLoggerWithPolicy 按指定的切割策略创建
+func NewAsyncWriteLoggerWithPolicy(filename string, policy RotatePolicy) (*WriterLogger, error) {
+	l := &WriterLogger{}
+	if policy.External {
+		l.writer = newReopenWriter(filename)
+	} else {
+		l.writer = newRotateWriter(filename, policy)
+	}
+	return l, nil
 }
 
-// WriteString 写日志
+// WriteString 写日志，关闭后返回 ErrClosed
 func (l *WriterLogger) WriteString(msg string) error {
-	_, err := l.writer.Write([]byte(msg))
+	_, err := l.Write([]byte(msg))
 	return err
 }
 
-// Close 关闭log
-func (l *WriterLogger) Close() error {
-	if l.closed {
-		return nil
+// Write 同 WriteString，返回前写入完成，调用方可以复用p
+func (l *WriterLogger) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&l.closed) == 1 {
+		return 0, ErrClosed
 	}
-	l.closed = true
-	if l.cancel != nil {
-		l.cancel()
-	}
-	l.wg.Wait()
-	return l.writer.Close()
+	return l.writer.Write(p)
 }
 
-func logLoop(l *WriterLogger) bool {
-	defer func() {
-		recover()
-	}()
+// Sync 将文件在系统缓存中的内容落盘，关闭前可以多次调用，关闭后不做任何事
+func (l *WriterLogger) Sync() error {
+	if atomic.LoadInt32(&l.closed) == 1 {
+		return nil
+	}
+	return l.writer.Sync()
+}
 
-	var msg string
-	closed := false
+// Rotate 立即切割日志文件，外部切割时重新打开文件
+func (l *WriterLogger) Rotate() error {
+	return l.writer.Rotate()
+}
 
-	for {
-		if !closed {
-			select {
-			case msg = <-l.msgChan:
-			case <-l.ctx.Done():
-				closed = true
-			}
-		} else {
-			select {
-			case msg = <-l.msgChan:
-			default:
-				return true
-			}
-		}
-		if len(msg) > 0 {
-			_ = l.WriteString(msg)
-			msg = ""
-		}
-	}
+// Close 关闭log，之后不能再写入
+func (l *WriterLogger) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		atomic.StoreInt32(&l.closed, 1)
+		err = l.writer.Close()
+	})
+	return err
 }
@@ -0,0 +1,167 @@
+package async
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 检查文件是否被移走的最小间隔，方便测试替换
+var reopenCheckInterval = time.Second
+
+// reopenWriter 写入普通文件，由外部的logrotate负责切割。
+// 文件被移走、删除(inode变化)或收到SIGHUP时重新打开，copytruncate方式下以追加模式写入不会产生空洞
+type reopenWriter struct {
+	mu        sync.Mutex
+	filename  string
+	file      *os.File
+	info      os.FileInfo
+	lastCheck time.Time
+}
+
+func newReopenWriter(filename string) *reopenWriter {
+	w := &reopenWriter{filename: filename}
+	hupWriters.add(w)
+	return w
+}
+
+// Write implements io.Writer
+func (w *reopenWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.reopenIfMoved(); err != nil {
+		return 0, err
+	}
+	return w.file.Write(p)
+}
+
+// Sync 落盘打开的文件，还没有写入时不做任何事
+func (w *reopenWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return errors.Wrap(w.file.Sync(), "can't sync log file")
+}
+
+// Rotate 由外部切割，这里只重新打开文件
+func (w *reopenWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.reopen()
+}
+
+// Close implements io.Closer
+func (w *reopenWriter) Close() error {
+	hupWriters.remove(w)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeFile()
+}
+
+// reopenIfMoved 文件未打开或路径对应的文件已经不是打开的文件时重新打开
+func (w *reopenWriter) reopenIfMoved() error {
+	if w.file == nil {
+		return w.reopen()
+	}
+
+	now := currentTime()
+	if now.Sub(w.lastCheck) < reopenCheckInterval {
+		return nil
+	}
+	w.lastCheck = now
+
+	info, err := os.Stat(w.filename)
+	if err == nil && os.SameFile(info, w.info) {
+		return nil
+	}
+	return w.reopen()
+}
+
+func (w *reopenWriter) reopen() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return errors.Wrap(err, "can't make directories for log file")
+	}
+
+	file, err := os.OpenFile(w.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrap(err, "can't open log file")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "can't stat log file")
+	}
+
+	w.file, w.info, w.lastCheck = file, info, currentTime()
+	return nil
+}
+
+func (w *reopenWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file, w.info = nil, nil
+	return err
+}
+
+// hupWriters 收到SIGHUP时重新打开所有外部切割的文件，有文件时才监听
+var hupWriters = &hupRegistry{writers: map[*reopenWriter]struct{}{}}
+
+type hupRegistry struct {
+	mu      sync.Mutex
+	writers map[*reopenWriter]struct{}
+	ch      chan os.Signal
+}
+
+func (r *hupRegistry) add(w *reopenWriter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writers[w] = struct{}{}
+	if r.ch != nil {
+		return
+	}
+	r.ch = make(chan os.Signal, 1)
+	signal.Notify(r.ch, syscall.SIGHUP)
+	go r.loop(r.ch)
+}
+
+func (r *hupRegistry) remove(w *reopenWriter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.writers, w)
+	if len(r.writers) == 0 && r.ch != nil {
+		signal.Stop(r.ch)
+		close(r.ch)
+		r.ch = nil
+	}
+}
+
+func (r *hupRegistry) loop(ch chan os.Signal) {
+	for range ch {
+		r.mu.Lock()
+		writers := make([]*reopenWriter, 0, len(r.writers))
+		for w := range r.writers {
+			writers = append(writers, w)
+		}
+		r.mu.Unlock()
+
+		for _, w := range writers {
+			_ = w.Rotate()
+		}
+	}
+}
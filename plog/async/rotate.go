@@ -0,0 +1,280 @@
+package async
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	megabyte = 1024 * 1024
+	// lumberjack 备份文件名中的时间格式
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+	// 不按大小切割时交给lumberjack的上限(MB)，实际不会触发
+	unlimitedFileSize = 1 << 30
+)
+
+// 方便测试替换
+var currentTime = time.Now
+
+// RotateInterval 按时间切割日志的周期
+type RotateInterval int
+
+const (
+	// RotateNone 不按时间切割
+	RotateNone RotateInterval = iota
+	// RotateHourly 每个整点切割
+	RotateHourly
+	// RotateDaily 每天本地时间零点切割
+	RotateDaily
+)
+
+// RotatePolicy 日志切割策略
+type RotatePolicy struct {
+	// MaxSize 单个日志文件最大尺寸(MB)，<=0 表示不按大小切割
+	MaxSize int
+	// Interval 按时间切割的周期
+	Interval RotateInterval
+	// MaxBackups 最多保留的备份文件个数，0 表示不限制
+	MaxBackups int
+	// MaxAge 备份文件最多保留的天数，0 表示不限制
+	MaxAge int
+	// Compress 是否gzip压缩备份文件
+	Compress bool
+	// MaxTotalSize 日志文件及其备份占用的磁盘总量上限(MB)，0 表示不限制
+	MaxTotalSize int
+	// OnRotate 每次切割完成后回调，参数为刚切出来的备份文件路径。
+	// 开启压缩时该文件随后会被异步压缩为 .gz
+	OnRotate func(backup string)
+	// External 由系统logrotate等外部工具切割，为true时以上配置都不生效，
+	// 文件被移走或收到SIGHUP时重新打开
+	External bool
+}
+
+// DefaultRotatePolicy 默认切割策略
+var DefaultRotatePolicy = RotatePolicy{
+	MaxSize:    maxFileSize,
+	MaxBackups: maxBackups,
+	MaxAge:     maxAge,
+}
+
+// rotateWriter 在lumberjack之上实现按时间切割、总量限制和切割回调。
+// 大小切割也由这里判断，保证每次切割都能触发回调
+type rotateWriter struct {
+	mu       sync.Mutex
+	logger   *lumberjack.Logger
+	policy   RotatePolicy
+	size     int64
+	opened   bool
+	nextRoll time.Time
+}
+
+func newRotateWriter(filename string, policy RotatePolicy) *rotateWriter {
+	maxSize := policy.MaxSize
+	if maxSize <= 0 {
+		maxSize = unlimitedFileSize
+	}
+	return &rotateWriter{
+		logger: &lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    maxSize,
+			MaxBackups: policy.MaxBackups,
+			MaxAge:     policy.MaxAge,
+			Compress:   policy.Compress,
+			LocalTime:  true,
+		},
+		policy: policy,
+	}
+}
+
+// Write implements io.Writer
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	backup, err := w.rotateIfNeeded(int64(len(p)))
+	if err != nil {
+		w.mu.Unlock()
+		return 0, err
+	}
+	n, err := w.logger.Write(p)
+	w.size += int64(n)
+	w.mu.Unlock()
+
+	if backup != "" && w.policy.OnRotate != nil {
+		w.policy.OnRotate(backup)
+	}
+	return n, err
+}
+
+// Rotate 立即切割
+func (w *rotateWriter) Rotate() error {
+	w.mu.Lock()
+	backup, err := w.rotate()
+	w.mu.Unlock()
+
+	if err == nil && backup != "" && w.policy.OnRotate != nil {
+		w.policy.OnRotate(backup)
+	}
+	return err
+}
+
+// Sync 落盘当前日志文件。lumberjack不暴露打开的文件，这里另外打开同一个文件调用fsync，
+// fsync作用于文件本身，会一并落盘通过lumberjack写入的内容
+func (w *rotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.opened {
+		return nil
+	}
+
+	file, err := os.Open(w.logger.Filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "can't open log file for sync")
+	}
+	err = file.Sync()
+	_ = file.Close()
+	return errors.Wrap(err, "can't sync log file")
+}
+
+// Close implements io.Closer
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.opened = false
+	return w.logger.Close()
+}
+
+func (w *rotateWriter) rotateIfNeeded(writeLen int64) (string, error) {
+	now := currentTime()
+	if !w.opened {
+		w.opened = true
+		w.size = 0
+		w.nextRoll = w.nextBoundary(now)
+		if info, err := os.Stat(w.logger.Filename); err == nil {
+			w.size = info.Size()
+			// 进程重启时已有文件属于之前的周期，需要先切出去
+			if w.policy.Interval != RotateNone && w.nextBoundary(info.ModTime()).Before(w.nextRoll) {
+				return w.rotate()
+			}
+			// 与lumberjack打开已有文件时的判断保持一致
+			if w.policy.MaxSize > 0 && w.size > 0 && w.size+writeLen >= w.maxBytes() {
+				return w.rotate()
+			}
+		}
+	}
+
+	if w.policy.Interval != RotateNone && !now.Before(w.nextRoll) {
+		w.nextRoll = w.nextBoundary(now)
+		return w.rotate()
+	}
+
+	if w.policy.MaxSize > 0 && w.size > 0 && w.size+writeLen > w.maxBytes() {
+		return w.rotate()
+	}
+	return "", nil
+}
+
+func (w *rotateWriter) maxBytes() int64 {
+	return int64(w.policy.MaxSize) * megabyte
+}
+
+func (w *rotateWriter) rotate() (string, error) {
+	_, statErr := os.Stat(w.logger.Filename)
+	if err := w.logger.Rotate(); err != nil {
+		return "", err
+	}
+	w.size = 0
+	if statErr != nil {
+		// 原文件不存在，没有产生备份
+		return "", nil
+	}
+
+	backups, err := w.backups()
+	if err != nil || len(backups) == 0 {
+		return "", err
+	}
+	w.removeOverTotalSize(backups)
+	return backups[0].path, nil
+}
+
+// nextBoundary 返回t之后的下一个切割时间点
+func (w *rotateWriter) nextBoundary(t time.Time) time.Time {
+	t = t.Local()
+	switch w.policy.Interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+type backupFile struct {
+	path      string
+	size      int64
+	timestamp time.Time
+}
+
+// backups 按时间从新到旧返回备份文件
+func (w *rotateWriter) backups() ([]backupFile, error) {
+	dir := filepath.Dir(w.logger.Filename)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Base(w.logger.Filename)
+	ext := filepath.Ext(filename)
+	prefix := filename[:len(filename)-len(ext)] + "-"
+
+	var ret []backupFile
+	for _, f := range files {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), prefix) {
+			continue
+		}
+		name := strings.TrimSuffix(f.Name(), compressSuffix)
+		if !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts, err := time.ParseInLocation(backupTimeFormat, name[len(prefix):len(name)-len(ext)], time.Local)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, backupFile{
+			path:      filepath.Join(dir, f.Name()),
+			size:      f.Size(),
+			timestamp: ts,
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].timestamp.After(ret[j].timestamp)
+	})
+	return ret, nil
+}
+
+// removeOverTotalSize 从最旧的备份开始删除，直到总量不超过 MaxTotalSize。
+// 刚切出来的备份总是保留，以便回调能拿到文件
+func (w *rotateWriter) removeOverTotalSize(backups []backupFile) {
+	if w.policy.MaxTotalSize <= 0 {
+		return
+	}
+	limit := int64(w.policy.MaxTotalSize) * megabyte
+	total := w.size
+	for i, b := range backups {
+		total += b.size
+		if i > 0 && total > limit {
+			_ = os.Remove(b.path)
+		}
+	}
+}
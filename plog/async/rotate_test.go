@@ -0,0 +1,189 @@
+package async
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotateBySize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plog_rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var rotated []string
+	w := newRotateWriter(filepath.Join(dir, "app.log"), RotatePolicy{
+		MaxSize:  1,
+		OnRotate: func(backup string) { rotated = append(rotated, backup) },
+	})
+	defer w.Close()
+
+	line := []byte(strings.Repeat("a", 1023) + "\n")
+	for i := 0; i < 1024; i++ {
+		if _, err = w.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(rotated) != 0 {
+		t.Fatalf("unexpected rotate: %v", rotated)
+	}
+
+	_, _ = w.Write(line)
+	if len(rotated) != 1 {
+		t.Fatalf("rotate count:%d", len(rotated))
+	}
+	if info, err := os.Stat(rotated[0]); err != nil || info.Size() != megabyte {
+		t.Fatalf("backup:%v err:%v", info, err)
+	}
+}
+
+func TestRotateByTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plog_rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2022, 8, 18, 23, 59, 0, 0, time.Local)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = time.Now }()
+
+	var rotated []string
+	w := newRotateWriter(filepath.Join(dir, "app.log"), RotatePolicy{
+		Interval: RotateDaily,
+		OnRotate: func(backup string) { rotated = append(rotated, backup) },
+	})
+	defer w.Close()
+
+	_, _ = w.Write([]byte("day1\n"))
+	now = now.Add(59 * time.Second)
+	_, _ = w.Write([]byte("day1\n"))
+	if len(rotated) != 0 {
+		t.Fatalf("unexpected rotate: %v", rotated)
+	}
+
+	// 跨过零点
+	now = now.Add(time.Second)
+	_, _ = w.Write([]byte("day2\n"))
+	if len(rotated) != 1 {
+		t.Fatalf("rotate count:%d", len(rotated))
+	}
+	data, _ := ioutil.ReadFile(rotated[0])
+	if string(data) != "day1\nday1\n" {
+		t.Fatalf("backup content:%q", data)
+	}
+
+	now = now.Add(23 * time.Hour)
+	_, _ = w.Write([]byte("day2\n"))
+	if len(rotated) != 1 {
+		t.Fatalf("unexpected rotate: %v", rotated)
+	}
+}
+
+func TestRotateMaxTotalSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plog_rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := newRotateWriter(filepath.Join(dir, "app.log"), RotatePolicy{
+		MaxTotalSize: 1,
+	})
+	defer w.Close()
+
+	chunk := []byte(strings.Repeat("a", 400*1024))
+	for i := 0; i < 4; i++ {
+		_, _ = w.Write(chunk)
+		if err = w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		// 备份文件名精确到毫秒
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("backups:%v", backups)
+	}
+}
+
+func TestReopenWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plog_reopen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	orgInterval := reopenCheckInterval
+	reopenCheckInterval = 0
+	defer func() { reopenCheckInterval = orgInterval }()
+
+	fileName := filepath.Join(dir, "app.log")
+	l, err := NewAsyncWriteLoggerWithPolicy(fileName, RotatePolicy{External: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	_ = l.WriteString("line1\n")
+	// logrotate 默认的 create 方式：移走后写入新文件
+	if err = os.Rename(fileName, fileName+".1"); err != nil {
+		t.Fatal(err)
+	}
+	_ = l.WriteString("line2\n")
+	// copytruncate 方式：原文件被清空，继续追加写入
+	if err = os.Truncate(fileName, 0); err != nil {
+		t.Fatal(err)
+	}
+	_ = l.WriteString("line3\n")
+
+	if data, _ := ioutil.ReadFile(fileName + ".1"); string(data) != "line1\n" {
+		t.Fatalf("moved:%q", data)
+	}
+	if data, _ := ioutil.ReadFile(fileName); string(data) != "line3\n" {
+		t.Fatalf("current:%q", data)
+	}
+}
+
+func TestReopenOnSIGHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported")
+	}
+	dir, err := ioutil.TempDir("", "plog_reopen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "app.log")
+	w := newReopenWriter(fileName)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("line1\n"))
+	_ = os.Rename(fileName, fileName+".1")
+
+	p, _ := os.FindProcess(os.Getpid())
+	_ = p.Signal(syscall.SIGHUP)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, err = os.Stat(fileName); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal("file not reopened after SIGHUP")
+	}
+}
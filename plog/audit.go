@@ -0,0 +1,81 @@
+package plog
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/async"
+	"github.com/pan-jf/go-utils/plog/audit"
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// AuditRotatePolicy 审计日志的默认切割策略，只按大小切割，不删除备份
+var AuditRotatePolicy = async.RotatePolicy{
+	MaxSize: async.DefaultRotatePolicy.MaxSize,
+}
+
+var (
+	auditMu     sync.Mutex
+	auditLogger *AuditLogger
+)
+
+// AuditLogger 审计日志。Info等方法与 Logger 相同，写入失败时只会输出到zap的ErrorOutput，
+// 需要确认写入成功时使用 Record
+type AuditLogger struct {
+	*Logger
+	core zapcore.Core
+}
+
+// Record 写入一条Info级别的审计日志，写入失败时返回错误，序号和hash不前进，调用方可以重试
+func (a *AuditLogger) Record(msg string, fields ...zap.Field) error {
+	entry := zapcore.Entry{
+		Level:   zapcore.InfoLevel,
+		Time:    time.Now(),
+		Message: msg,
+		Caller:  zapcore.NewEntryCaller(runtime.Caller(1)),
+	}
+	return a.core.Write(entry, fields)
+}
+
+// Audit 返回审计日志，与默认实例的主日志同目录，文件名为 日志名.audit.log，首次调用时创建。
+// 创建失败时返回错误，下次调用会重新创建，调用方可以重试或在初始化时检查。
+// 审计日志不受级别、采样和脱敏配置影响，每条日志带有序号和串联的hash，
+// 可以通过 audit.VerifyFiles 或 cmd/auditverify 校验是否被删改
+func Audit() (*AuditLogger, error) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	if auditLogger != nil {
+		return auditLogger, nil
+	}
+	logger, err := NewAuditLogger(std.auditFilePath(), AuditRotatePolicy)
+	if err != nil {
+		return nil, err
+	}
+	auditLogger = logger
+	return logger, nil
+}
+
+// auditFilePath 按创建实例的配置得到审计日志的路径
+func (l *Logger) auditFilePath() string {
+	config := l.Options()
+	return util.GetOutputFilePath(&config, util.FileOutput{Name: "audit"})
+}
+
+// NewAuditLogger 创建写入fileName的审计日志，文件已存在时接着之前的序号和hash写入
+func NewAuditLogger(fileName string, policy async.RotatePolicy) (*AuditLogger, error) {
+	core, err := audit.NewCore(fileName, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	withGoID := filter.NewGoIDCore(core)
+	logger := newLogger(zap.New(withGoID, zap.AddCaller(), zap.AddCallerSkip(1)))
+	logger.closer = core
+	return &AuditLogger{Logger: logger, core: withGoID}, nil
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/async"
+)
+
+func writeEntries(t *testing.T, fileName string, n int) {
+	core, err := NewCore(fileName, async.RotatePolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := zap.New(core).With(zap.String("app", "test"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logger.Info("audit", zap.Int("i", i), zap.Uint64("seq", 1000))
+		}(i)
+	}
+	wg.Wait()
+	if err = core.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plog_audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "app.audit.log")
+
+	writeEntries(t, fileName, 50)
+	// 重新打开后接着之前的序号
+	writeEntries(t, fileName, 10)
+
+	if n, err := VerifyFiles(fileName); err != nil || n != 60 {
+		t.Fatalf("verify:%d %v", n, err)
+	}
+
+	data, _ := ioutil.ReadFile(fileName)
+	lines := strings.SplitAfter(string(data), "\n")
+
+	modified := append([]string(nil), lines...)
+	modified[5] = strings.Replace(modified[5], `"app":"test"`, `"app":"evil"`, 1)
+	assertVerifyError(t, fileName, strings.Join(modified, ""), 6, "modified")
+
+	removed := append(append([]string(nil), lines[:20]...), lines[21:]...)
+	assertVerifyError(t, fileName, strings.Join(removed, ""), 21, "missing")
+}
+
+func assertVerifyError(t *testing.T, fileName, content string, line int, reason string) {
+	t.Helper()
+	if err := ioutil.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := VerifyFiles(fileName)
+	verr, ok := err.(*VerifyError)
+	if !ok || verr.Line != line || !strings.Contains(verr.Reason, reason) {
+		t.Fatalf("err:%v", err)
+	}
+}
+
+// flakyWriter 第fail次写入失败
+type flakyWriter struct {
+	bytes.Buffer
+	writes int
+	fail   int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes == w.fail {
+		return 0, errors.New("disk full")
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *flakyWriter) Sync() error {
+	return nil
+}
+
+func TestChainWriteError(t *testing.T) {
+	out := &flakyWriter{fail: 2}
+	state := &chainState{}
+	encoder := &chainEncoder{Encoder: zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), state: state}
+	core := &Core{Core: zapcore.NewCore(encoder, out, zapcore.DebugLevel), mu: &sync.Mutex{}, state: state}
+
+	var errs []error
+	for i := 0; i < 3; i++ {
+		errs = append(errs, core.Write(zapcore.Entry{Message: "audit"}, []zapcore.Field{zap.Int("i", i)}))
+	}
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Fatalf("errs:%v", errs)
+	}
+
+	// 失败的日志不占用序号，文件中的链仍然完整
+	verifier := &Verifier{}
+	if err := verifier.Verify("audit", &out.Buffer); err != nil || verifier.Entries != 2 {
+		t.Fatalf("entries:%d err:%v", verifier.Entries, err)
+	}
+	if state.seq != 2 {
+		t.Fatalf("seq:%d", state.seq)
+	}
+}
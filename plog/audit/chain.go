@@ -0,0 +1,217 @@
+// Package audit 审计日志，每条日志带有递增的序号和与上一条日志串联的hash，
+// 日志被删改或缺失时可以通过 Verifier 检查出来
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/async"
+)
+
+// 审计日志固定字段的key
+const (
+	SeqKey  = "seq"
+	PrevKey = "prev"
+	HashKey = "hash"
+)
+
+// hashPrefix 每行日志以 ,"hash":"<hex>"} 结尾
+const hashPrefix = `,"` + HashKey + `":"`
+
+var bufferPool = buffer.NewPool()
+
+// chainState 所有派生的core共用。编码时算出的序号和hash先放在next中，
+// 写入成功后才前进，写入失败的日志不占用序号
+type chainState struct {
+	seq      uint64
+	prev     string
+	nextSeq  uint64
+	nextPrev string
+}
+
+func (s *chainState) commit() {
+	s.seq, s.prev = s.nextSeq, s.nextPrev
+}
+
+// chainEncoder 在json编码的基础上加上序号、上一条的hash和本条的hash
+type chainEncoder struct {
+	zapcore.Encoder
+	state *chainState
+}
+
+// Clone ...
+func (e *chainEncoder) Clone() zapcore.Encoder {
+	return &chainEncoder{Encoder: e.Encoder.Clone(), state: e.state}
+}
+
+// EncodeEntry 调用方需要保证编码和写入的顺序一致，写入成功后调用 chainState.commit
+func (e *chainEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	seq := e.state.seq + 1
+	withChain := make([]zapcore.Field, 0, len(fields)+2)
+	withChain = append(withChain, fields...)
+	withChain = append(withChain, zap.Uint64(SeqKey, seq), zap.String(PrevKey, e.state.prev))
+
+	encoded, err := e.Encoder.EncodeEntry(entry, withChain)
+	if err != nil {
+		return nil, err
+	}
+	defer encoded.Free()
+
+	body := bytes.TrimRight(encoded.Bytes(), "\n")
+	hash := hashLine(body)
+
+	buf := bufferPool.Get()
+	_, _ = buf.Write(body[:len(body)-1])
+	buf.AppendString(hashPrefix)
+	buf.AppendString(hash)
+	buf.AppendString("\"}\n")
+
+	e.state.nextSeq, e.state.nextPrev = seq, hash
+	return buf, nil
+}
+
+func hashLine(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Core 审计日志的core，写入所有级别，不采样不丢弃
+type Core struct {
+	zapcore.Core
+	mu    *sync.Mutex
+	state *chainState
+}
+
+// NewCore 写入fileName，文件已存在时从最后一行接着串联。
+// 审计日志需要完整保留，切割策略中不应设置 MaxBackups、MaxAge 等会删除文件的配置
+func NewCore(fileName string, policy async.RotatePolicy) (*Core, error) {
+	state, err := lastState(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	encConf := zap.NewProductionEncoderConfig()
+	encConf.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	encoder := &chainEncoder{Encoder: zapcore.NewJSONEncoder(encConf), state: state}
+
+	core, err := async.NewAsyncFileCoreWithPolicy(zapcore.DebugLevel, encoder, fileName, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &Core{Core: core, mu: &sync.Mutex{}, state: state}, nil
+}
+
+// With ...
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	return &Core{Core: c.Core.With(fields), mu: c.mu, state: c.state}
+}
+
+// Check ...
+func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write 编码和写入加锁，保证文件中的顺序与序号一致。
+// 写入失败时返回错误，序号和hash不前进，下一条日志仍接在最后写入成功的日志之后
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.Core.Write(entry, fields); err != nil {
+		return err
+	}
+	c.state.commit()
+	return nil
+}
+
+// Close 关闭审计日志文件
+func (c *Core) Close() error {
+	if closer, ok := c.Core.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// lastState 读取已有文件的最后一行，文件不存在或为空时从头开始
+func lastState(fileName string) (*chainState, error) {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return &chainState{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "open audit log")
+	}
+	defer file.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read audit log")
+	}
+	if last == nil {
+		return &chainState{}, nil
+	}
+
+	line, err := parseLine(last)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse last line of audit log")
+	}
+	return &chainState{seq: line.seq, prev: line.hash}, nil
+}
+
+type line struct {
+	seq  uint64
+	prev string
+	hash string
+	body []byte
+}
+
+// parseLine 拆出hash和参与hash计算的内容
+func parseLine(data []byte) (*line, error) {
+	i := bytes.LastIndex(data, []byte(hashPrefix))
+	if i < 0 || !bytes.HasSuffix(data, []byte("\"}")) {
+		return nil, errors.New("missing hash")
+	}
+
+	body := make([]byte, 0, i+1)
+	body = append(body, data[:i]...)
+	body = append(body, '}')
+
+	var fields struct {
+		Seq  *uint64 `json:"seq"`
+		Prev *string `json:"prev"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, errors.Wrap(err, "invalid json")
+	}
+	if fields.Seq == nil || fields.Prev == nil {
+		return nil, errors.New("missing seq or prev")
+	}
+
+	return &line{
+		seq:  *fields.Seq,
+		prev: *fields.Prev,
+		hash: string(data[i+len(hashPrefix) : len(data)-2]),
+		body: body,
+	}, nil
+}
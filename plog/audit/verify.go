@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// 单行日志的最大长度
+const maxLineSize = 64 * 1024 * 1024
+
+// VerifyError 校验失败的位置和原因
+type VerifyError struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+// Error ...
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
+}
+
+// Verifier 按顺序校验一个或多个审计日志文件，跨文件时串联关系同样需要连续。
+// 第一条日志的prev无法校验，作为起点
+type Verifier struct {
+	started bool
+	seq     uint64
+	hash    string
+	// Entries 已校验通过的条数
+	Entries int
+}
+
+// Verify 校验r中的日志，name用于错误信息
+func (v *Verifier) Verify(name string, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		fail := func(format string, args ...interface{}) error {
+			return &VerifyError{File: name, Line: n, Reason: fmt.Sprintf(format, args...)}
+		}
+
+		l, err := parseLine(scanner.Bytes())
+		if err != nil {
+			return fail("%v", err)
+		}
+		if hash := hashLine(l.body); hash != l.hash {
+			return fail("hash mismatch, line modified")
+		}
+		if v.started {
+			if l.seq != v.seq+1 {
+				return fail("seq %d follows %d, entries missing", l.seq, v.seq)
+			}
+			if l.prev != v.hash {
+				return fail("prev hash mismatch, entries missing or replaced")
+			}
+		}
+
+		v.started, v.seq, v.hash = true, l.seq, l.hash
+		v.Entries++
+	}
+	return errors.Wrapf(scanner.Err(), "read %s", name)
+}
+
+// VerifyFiles 按给出的顺序校验文件，切割出的备份文件需要按时间顺序放在当前文件之前
+func VerifyFiles(names ...string) (int, error) {
+	v := &Verifier{}
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			return v.Entries, errors.Wrap(err, "open audit log")
+		}
+		err = v.Verify(name, file)
+		_ = file.Close()
+		if err != nil {
+			return v.Entries, err
+		}
+	}
+	return v.Entries, nil
+}
@@ -0,0 +1,75 @@
+package plog
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+func TestAudit(t *testing.T) {
+	org := Default()
+	t.Cleanup(func() {
+		SetDefault(org)
+		auditMu.Lock()
+		if auditLogger != nil {
+			_ = auditLogger.Close()
+			auditLogger = nil
+		}
+		auditMu.Unlock()
+	})
+
+	// 目录不可用时返回错误，不缓存失败的结果
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "blocked")
+	if err := ioutil.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	bad := &Logger{options: &util.Options{}}
+	util.LogDir(blocked)(bad.options)
+	SetDefault(bad)
+	if logger, err := Audit(); err == nil || logger != nil {
+		t.Fatalf("logger:%v err:%v", logger, err)
+	}
+
+	// 路径按默认实例的配置
+	logger, err := New(util.LogDir(dir), util.LogName("plog_audit_test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	SetDefault(logger)
+
+	audited, err := Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := Audit(); again != audited {
+		t.Fatal("audit logger not reused")
+	}
+	audited.Info("audit entry")
+	if err = audited.Record("recorded entry", zap.String("op", "login")); err != nil {
+		t.Fatal(err)
+	}
+	_ = audited.Sync()
+
+	path := util.GetOutputFilePath(logger.options, util.FileOutput{Name: "audit"})
+	if !strings.HasSuffix(path, "plog_audit_test.audit.log") {
+		t.Fatalf("path:%s", path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "audit entry") ||
+		!strings.Contains(string(data), `"op":"login"`) || !strings.Contains(string(data), "audit_test.go") {
+		t.Fatalf("content:%s err:%v", data, err)
+	}
+
+	// 写入失败时返回错误
+	_ = audited.Close()
+	if err = audited.Record("after close"); err == nil {
+		t.Fatal("expect error after close")
+	}
+}
@@ -0,0 +1,204 @@
+package plog
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+const benchMsg = "request finished"
+
+func benchEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+}
+
+func discardCore() zapcore.Core {
+	return zapcore.NewCore(benchEncoder(), zapcore.AddSync(ioutil.Discard), zapcore.DebugLevel)
+}
+
+// newBenchLogger 与 mode.LogInit 相同的core组合，输出到core
+func newBenchLogger(core zapcore.Core, lvl zapcore.Level, opts ...util.Option) *Logger {
+	config := util.DefaultLogOptions
+	for _, opt := range opts {
+		opt(&config)
+	}
+	core = filter.NewGoIDCore(core)
+	core = filter.NewTruncateCore(core, util.GetMaxMsgSize(&config), nil)
+	core = filter.NewRedactCore(core, util.GetRedactor(&config))
+	core = filter.NewSampleCore(core, util.GetSampleConfig(&config))
+
+	level := zap.NewAtomicLevelAt(lvl)
+	logger := newLogger(zap.New(filter.NewLevelCore(filter.NewSwapCore(core), level),
+		zap.AddCaller(), zap.AddCallerSkip(1)))
+	logger.level = level
+	return logger
+}
+
+func benchContext() context.Context {
+	return WithContext(context.Background(), util.TraceID("4bf92f3577b34da6a3ce929d0e0e4736"), util.UserID("10086"))
+}
+
+func runBenchmarks(b *testing.B, benchmarks []struct {
+	name string
+	log  func()
+}) {
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bm.log()
+			}
+		})
+	}
+}
+
+// BenchmarkInfo plog与直接使用zap的对比，都输出json到 ioutil.Discard
+func BenchmarkInfo(b *testing.B) {
+	logger := newBenchLogger(discardCore(), zapcore.InfoLevel)
+	zl := zap.New(discardCore(), zap.AddCaller())
+	ctx := benchContext()
+
+	runBenchmarks(b, []struct {
+		name string
+		log  func()
+	}{
+		{"plog", func() {
+			logger.Info(benchMsg)
+		}},
+		{"plog_fields", func() {
+			logger.Info(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200), zap.Duration("cost", time.Millisecond))
+		}},
+		{"plog_ctx", func() {
+			logger.Ctx(ctx).Info(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200), zap.Duration("cost", time.Millisecond))
+		}},
+		{"plog_sugar", func() {
+			logger.Infow(benchMsg, "path", "/api/user", "status", 200, "cost", time.Millisecond)
+		}},
+		{"zap", func() {
+			zl.Info(benchMsg)
+		}},
+		{"zap_fields", func() {
+			zl.Info(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200), zap.Duration("cost", time.Millisecond))
+		}},
+		{"zap_with", func() {
+			zl.With(ContextFields(ctx)...).Info(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200), zap.Duration("cost", time.Millisecond))
+		}},
+	})
+}
+
+type benchUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Age   int    `json:"age"`
+}
+
+// BenchmarkRedact 默认开启的脱敏的开销，对比 util.DisableRedact
+func BenchmarkRedact(b *testing.B) {
+	logger := newBenchLogger(discardCore(), zapcore.InfoLevel)
+	plain := newBenchLogger(discardCore(), zapcore.InfoLevel, util.DisableRedact())
+	user := &benchUser{Name: "pan", Email: "pan@example.com", Age: 18}
+
+	runBenchmarks(b, []struct {
+		name string
+		log  func()
+	}{
+		{"fields", func() {
+			logger.Info(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200))
+		}},
+		{"fields_noredact", func() {
+			plain.Info(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200))
+		}},
+		{"reflect", func() {
+			logger.Info(benchMsg, zap.Any("user", user))
+		}},
+		{"reflect_noredact", func() {
+			plain.Info(benchMsg, zap.Any("user", user))
+		}},
+		{"reflect_masked", func() {
+			logger.Info(benchMsg, zap.Any("user", map[string]string{"name": "pan", "password": "p"}))
+		}},
+	})
+}
+
+// BenchmarkDisabled 级别未开启的日志，如线上的Debug
+func BenchmarkDisabled(b *testing.B) {
+	logger := newBenchLogger(discardCore(), zapcore.InfoLevel)
+	named := logger.Named("bench")
+	zl := zap.New(discardCore(), zap.AddCaller(), zap.IncreaseLevel(zapcore.InfoLevel))
+	ctx := benchContext()
+
+	runBenchmarks(b, []struct {
+		name string
+		log  func()
+	}{
+		{"plog", func() {
+			logger.Debug(benchMsg)
+		}},
+		{"plog_fields", func() {
+			logger.Debug(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200))
+		}},
+		{"plog_named", func() {
+			named.Debug(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200))
+		}},
+		{"plog_ctx", func() {
+			logger.Ctx(ctx).Debug(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200))
+		}},
+		{"plog_sugar", func() {
+			logger.Debugf("%s %d", "/api/user", 200)
+		}},
+		{"zap_fields", func() {
+			zl.Debug(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200))
+		}},
+	})
+}
+
+func TestDisabledZeroAlloc(t *testing.T) {
+	logger := newBenchLogger(discardCore(), zapcore.InfoLevel)
+	named := logger.Named("alloc")
+	with := logger.With(zap.String("k", "v"))
+	ctx := benchContext()
+	ctxLogger := logger.Ctx(ctx)
+	plain := logger.Ctx(context.Background())
+
+	// 在t.Cleanup中恢复，与后面 NewTestLogger 的恢复按相反顺序执行
+	org := std
+	SetDefault(logger)
+	t.Cleanup(func() { SetDefault(org) })
+
+	tests := []struct {
+		name   string
+		log    func()
+		allocs float64
+	}{
+		{"package", func() { Debug(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200)) }, 0},
+		{"logger", func() { logger.Debug(benchMsg, zap.String("path", "/api/user"), zap.Int("status", 200)) }, 0},
+		{"named", func() { named.Debug(benchMsg, zap.String("path", "/api/user")) }, 0},
+		{"with", func() { with.Debug(benchMsg, zap.String("path", "/api/user")) }, 0},
+		{"ctx", func() { ctxLogger.Debug(benchMsg, zap.String("path", "/api/user")) }, 0},
+		{"log", func() { logger.Log(zapcore.DebugLevel, benchMsg, zap.Int("status", 200)) }, 0},
+		{"sugar", func() { logger.Debugw(benchMsg, "status", 200) }, 0},
+		// 只分配派生的Logger，ctx中的字段在输出时才附加
+		{"derive_ctx", func() { logger.Ctx(ctx).Debug(benchMsg) }, 1},
+	}
+	for _, tt := range tests {
+		if allocs := testing.AllocsPerRun(100, tt.log); allocs > tt.allocs {
+			t.Errorf("%s: %v allocs, want <= %v", tt.name, allocs, tt.allocs)
+		}
+	}
+	if plain != logger {
+		t.Errorf("Ctx without fields should return the logger itself")
+	}
+
+	logs := NewTestLogger(t)
+	Ctx(ctx).Debug("lazy", zap.String("path", "/api/user"))
+	if fields := logs.AllUntimed()[0].ContextMap(); fields["TraceID"] == nil || fields["path"] != "/api/user" {
+		t.Fatalf("fields:%v", fields)
+	}
+}
@@ -0,0 +1,40 @@
+package plog
+
+import (
+	"context"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+)
+
+type bufferKey struct{}
+
+// WithBuffer 开启请求级的日志缓冲，返回的ctx及其派生的ctx通过 Ctx 输出的日志中，
+// 低于全局级别的Debug日志先暂存在内存中，同一范围内输出Error时一起写出，否则在调用
+// 返回的结束函数时丢弃，用法
+//
+//	ctx, done := plog.WithBuffer(ctx, filter.BufferConfig{})
+//	defer done()
+//	plog.Ctx(ctx).Debug("request", zap.Any("req", req))
+func WithBuffer(ctx context.Context, config filter.BufferConfig) (context.Context, func()) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	buffer := filter.NewBuffer(config)
+	return context.WithValue(ctx, bufferKey{}, buffer), buffer.Close
+}
+
+// FlushBuffer 不输出Error也需要保留暂存的日志时调用，如请求返回了失败的状态码，
+// 之后同一范围内的日志直接输出
+func FlushBuffer(ctx context.Context) {
+	if buffer := contextBuffer(ctx); buffer != nil {
+		buffer.Flush()
+	}
+}
+
+func contextBuffer(ctx context.Context) *filter.Buffer {
+	if ctx == nil {
+		return nil
+	}
+	buffer, _ := ctx.Value(bufferKey{}).(*filter.Buffer)
+	return buffer
+}
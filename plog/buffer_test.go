@@ -0,0 +1,48 @@
+package plog
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+)
+
+func TestWithBuffer(t *testing.T) {
+	logs := NewTestLogger(t)
+	SetLevel(zap.InfoLevel)
+
+	ok, done := WithBuffer(context.Background(), filter.BufferConfig{})
+	Ctx(ok).Debug("ok debug")
+	done()
+
+	failed, done := WithBuffer(context.Background(), filter.BufferConfig{})
+	defer done()
+	// 在另一个协程中暂存，字段的值在写出前被修改
+	state := map[string]string{"step": "begin"}
+	goID := make(chan int64)
+	go func() {
+		Ctx(failed).Debug("failed debug", zap.Int("n", 1), zap.Any("state", state))
+		goID <- filter.CurrentGoID()
+	}()
+	id := <-goID
+	state["step"] = "end"
+	Ctx(context.Background()).Debug("no buffer")
+	Ctx(failed).Errorf("%s", "failed")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 || entries[0].Message != "failed debug" || entries[1].Message != "failed" {
+		t.Fatalf("entries:%v", entries)
+	}
+	fields := entries[0].ContextMap()
+	if fields["n"] != int64(1) || fields["GoID"] != id || fields["GoID"] == entries[1].ContextMap()["GoID"] {
+		t.Fatalf("fields:%v", fields)
+	}
+	if s, _ := fields["state"].(map[string]interface{}); s["step"] != "begin" {
+		t.Fatalf("state:%v", fields["state"])
+	}
+	if entries[0].Caller.TrimmedPath() == entries[1].Caller.TrimmedPath() || !entries[0].Caller.Defined {
+		t.Fatalf("caller:%v %v", entries[0].Caller, entries[1].Caller)
+	}
+}
@@ -0,0 +1,27 @@
+// auditverify 校验审计日志是否被删改或缺失，用法
+//
+//	auditverify app.audit-2022-08-01T00-00-00.000.log app.audit.log
+//
+// 多个文件按时间顺序给出，校验通过时退出码为0
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pan-jf/go-utils/plog/audit"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: auditverify file...")
+		os.Exit(2)
+	}
+
+	n, err := audit.VerifyFiles(os.Args[1:]...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify failed after %d entries: %v\n", n, err)
+		os.Exit(1)
+	}
+	fmt.Printf("ok, %d entries\n", n)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// keys 日志固定字段的key，默认与线上json格式一致
+type keys struct {
+	time    string
+	level   string
+	logger  string
+	caller  string
+	message string
+	stack   string
+}
+
+var defaultKeys = keys{
+	time:    "ts",
+	level:   "level",
+	logger:  "logger",
+	caller:  "caller",
+	message: "msg",
+	stack:   "stacktrace",
+}
+
+// entry 解析后的一行日志，字段保持原来的顺序
+type entry struct {
+	names  []string
+	values map[string]json.RawMessage
+
+	time    time.Time
+	level   zapcore.Level
+	message string
+}
+
+// parseEntry 解析json格式的一行，不是json对象时返回false
+func parseEntry(line []byte, k *keys, timeLayout string) (*entry, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return nil, false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+
+	e := &entry{values: make(map[string]json.RawMessage), level: zapcore.InfoLevel}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		name, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, false
+		}
+		if _, ok := e.values[name]; !ok {
+			e.names = append(e.names, name)
+		}
+		e.values[name] = value
+	}
+	if _, err := dec.Token(); err != nil && err != io.EOF {
+		return nil, false
+	}
+
+	e.time = parseTime(e.values[k.time], timeLayout)
+	if lvl := e.str(k.level); lvl != "" {
+		_ = e.level.UnmarshalText([]byte(lvl))
+	}
+	e.message = e.str(k.message)
+	return e, true
+}
+
+// parseTime 支持plog的各种时间格式，字符串先按layout解析，数字按 epoch_millis 解析
+func parseTime(raw json.RawMessage, layout string) time.Time {
+	if len(raw) == 0 {
+		return time.Time{}
+	}
+	if raw[0] != '"' {
+		ms, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil {
+			return time.Time{}
+		}
+		return time.Unix(0, int64(ms*float64(time.Millisecond)))
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return time.Time{}
+	}
+	for _, l := range []string{layout, util.TimeFormatDefault, util.TimeFormatRFC3339Nano} {
+		if t, err := time.ParseInLocation(l, s, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// str 字段的文本，字符串去掉引号，其它类型为json原文
+func (e *entry) str(name string) string {
+	return rawString(e.values[name])
+}
+
+// lookup 支持用 a.b 访问嵌套对象中的字段
+func (e *entry) lookup(path string) (string, bool) {
+	if raw, ok := e.values[path]; ok {
+		return rawString(raw), true
+	}
+
+	parts := strings.Split(path, ".")
+	raw, ok := e.values[parts[0]]
+	if !ok {
+		return "", false
+	}
+	for _, part := range parts[1:] {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return "", false
+		}
+		if raw, ok = obj[part]; !ok {
+			return "", false
+		}
+	}
+	return rawString(raw), true
+}
+
+func rawString(raw json.RawMessage) string {
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+	}
+	return string(raw)
+}
+
+// matcher 所有条件都满足时输出
+type matcher struct {
+	minLevel *zapcore.Level
+	since    time.Time
+	until    time.Time
+	message  *regexp.Regexp
+	fields   map[string]string
+}
+
+// active 是否设置了任何条件，没有条件时非json的行原样输出
+func (f *matcher) active() bool {
+	return f.minLevel != nil || !f.since.IsZero() || !f.until.IsZero() || f.message != nil || len(f.fields) > 0
+}
+
+func (f *matcher) match(e *entry) bool {
+	if f.minLevel != nil && e.level < *f.minLevel {
+		return false
+	}
+	if !f.since.IsZero() && (e.time.IsZero() || e.time.Before(f.since)) {
+		return false
+	}
+	if !f.until.IsZero() && (e.time.IsZero() || !e.time.Before(f.until)) {
+		return false
+	}
+	if f.message != nil && !f.message.MatchString(e.message) {
+		return false
+	}
+	for name, want := range f.fields {
+		if got, ok := e.lookup(name); !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTimeFlag 时间参数可以是日志中的时间格式、RFC3339，或表示多久之前的时长如 10m
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	var lastErr error
+	for _, layout := range []string{util.TimeFormatDefault, "2006-01-02", time.RFC3339Nano} {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// 与 async 中lumberjack备份文件名的时间格式一致
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+	// 单行日志的最大长度
+	maxLineSize = 16 * 1024 * 1024
+)
+
+// 方便测试修改
+var followInterval = 200 * time.Millisecond
+
+// withBackups 在每个日志文件前加上它的备份文件，按时间从旧到新
+func withBackups(files []string) ([]string, error) {
+	var ret []string
+	for _, file := range files {
+		backups, err := backupsOf(file)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, backups...)
+		ret = append(ret, file)
+	}
+	return ret, nil
+}
+
+// backupsOf 返回 app.log 的备份文件 app-<时间>.log 及压缩后的 app-<时间>.log.gz
+func backupsOf(file string) ([]string, error) {
+	ext := filepath.Ext(file)
+	prefix := strings.TrimSuffix(file, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		path string
+		ts   time.Time
+	}
+	var backups []backup
+	for _, path := range matches {
+		name := strings.TrimSuffix(path, compressSuffix)
+		if !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts, err := time.ParseInLocation(backupTimeFormat, name[len(prefix):len(name)-len(ext)], time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: path, ts: ts})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ts.Before(backups[j].ts)
+	})
+
+	ret := make([]string, 0, len(backups))
+	for _, b := range backups {
+		ret = append(ret, b.path)
+	}
+	return ret, nil
+}
+
+// readFile 逐行读取，.gz 文件自动解压
+func readFile(path string, fn func(line []byte)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, compressSuffix) {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return readLines(r, fn)
+}
+
+func readLines(r io.Reader, fn func(line []byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+	return scanner.Err()
+}
+
+// follow 从文件末尾开始持续读取新写入的行，文件被切割或截断后重新从头读取，
+// 直到done被关闭
+func follow(path string, done <-chan struct{}, fn func(line []byte)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+	var pending []byte
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			offset += int64(len(line))
+			if line[len(line)-1] != '\n' {
+				// 行还没写完，等下次读取补齐
+				pending = append(pending, line...)
+				continue
+			}
+			fn(append(pending, line...))
+			pending = pending[:0]
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		select {
+		case <-done:
+			return nil
+		case <-time.After(followInterval):
+		}
+
+		if !rotated(f, path, offset) {
+			continue
+		}
+		// 读完旧文件剩余的内容再切换
+		if rest, _ := io.ReadAll(reader); len(rest) > 0 {
+			_ = readLines(strings.NewReader(string(append(pending, rest...))), fn)
+		}
+		pending = pending[:0]
+
+		next, err := os.Open(path)
+		if err != nil {
+			// 新文件可能还没创建，下次再试
+			continue
+		}
+		f.Close()
+		f, offset = next, 0
+		reader.Reset(f)
+	}
+}
+
+// rotated 文件被移走换成了新文件，或被截断
+func rotated(f *os.File, path string, offset int64) bool {
+	cur, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	old, err := f.Stat()
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(cur, old) || cur.Size() < offset
+}
@@ -0,0 +1,173 @@
+// plogcat 查看和过滤plog输出的json日志，用法
+//
+//	plogcat -level warn -since 1h -field UserID=u1 /data/plog/app/app.log
+//	plogcat -backups -grep 'timeout|refused' /data/plog/app/app.log
+//	plogcat -f -n 20 -goid 42 /data/plog/app/app.log
+//
+// 多个文件按给出的顺序读取，-backups 时先读取每个文件的备份(包括 .gz)，
+// -f 时读完后继续跟踪最后一个文件
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// fieldFlags 可重复的 -field key=value
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldFlags) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return fmt.Errorf("expect key=value: %s", s)
+	}
+	f[s[:i]] = s[i+1:]
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "plogcat:", err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	var (
+		flags      = flag.NewFlagSet("plogcat", flag.ContinueOnError)
+		k          = defaultKeys
+		fields     = fieldFlags{}
+		f          matcher
+		level      = flags.String("level", "", "最低级别，如 warn")
+		since      = flags.String("since", "", "开始时间，如 \"2022-08-01 10:00:00\"、RFC3339 或 1h 表示一小时前")
+		until      = flags.String("until", "", "结束时间(不包含)，格式同 -since")
+		goid       = flags.String("goid", "", "只看该协程的日志")
+		grep       = flags.String("grep", "", "消息匹配的正则")
+		layout     = flags.String("time-format", util.TimeFormatDefault, "日志中的时间格式，数字时间按 epoch_millis 解析")
+		backups    = flags.Bool("backups", false, "同时读取切割出的备份文件")
+		tail       = flags.Int("n", 0, "只输出最后n条匹配的日志，0 表示全部")
+		followFile = flags.Bool("f", false, "读完后继续跟踪最后一个文件")
+		raw        = flags.Bool("json", false, "原样输出json")
+		color      = flags.String("color", "auto", "auto、always 或 never")
+	)
+	flags.Var(fields, "field", "字段等于该值，可以重复，嵌套字段用 a.b，如 -field UserID=u1")
+	flags.StringVar(&k.time, "time-key", k.time, "时间字段的key")
+	flags.StringVar(&k.level, "level-key", k.level, "级别字段的key")
+	flags.StringVar(&k.message, "msg-key", k.message, "消息字段的key")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: plogcat [flags] file...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("no file")
+	}
+
+	now := time.Now()
+	if *level != "" {
+		lvl, err := zapcore.ParseLevel(*level)
+		if err != nil {
+			return err
+		}
+		f.minLevel = &lvl
+	}
+	var err error
+	if *since != "" {
+		if f.since, err = parseTimeFlag(*since, now); err != nil {
+			return err
+		}
+	}
+	if *until != "" {
+		if f.until, err = parseTimeFlag(*until, now); err != nil {
+			return err
+		}
+	}
+	if *grep != "" {
+		if f.message, err = regexp.Compile(*grep); err != nil {
+			return err
+		}
+	}
+	if *goid != "" {
+		fields[filter.GoIDKey] = *goid
+	}
+	if len(fields) > 0 {
+		f.fields = fields
+	}
+
+	files := flags.Args()
+	if *backups {
+		if files, err = withBackups(files); err != nil {
+			return err
+		}
+	}
+
+	p := newPrinter(stdout, &k, *raw, useColor(*color), *tail)
+	handle := func(line []byte) {
+		e, ok := parseEntry(line, &k, *layout)
+		if !ok {
+			if !f.active() {
+				p.Print(nil, line)
+			}
+			return
+		}
+		if f.match(e) {
+			p.Print(e, line)
+		}
+	}
+
+	for _, file := range files {
+		if err := readFile(file, handle); err != nil {
+			return err
+		}
+	}
+	if err := p.Flush(); err != nil || !*followFile {
+		return err
+	}
+
+	done := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		close(done)
+	}()
+	return follow(files[len(files)-1], done, func(line []byte) {
+		handle(line)
+		_ = p.Flush()
+	})
+}
+
+// useColor auto 时输出到终端才带颜色
+func useColor(mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	stat, err := os.Stdout.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
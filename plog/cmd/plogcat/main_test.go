@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	data := []byte(strings.Join(lines, "\n") + "\n")
+	if strings.HasSuffix(path, compressSuffix) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write(data)
+		_ = gz.Close()
+		data = buf.Bytes()
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+	writeFile(t, filepath.Join(dir, "app-2022-08-01T09-00-00.000.log.gz"),
+		`{"level":"info","ts":"2022-08-01 08:59:00","caller":"app/main.go:10","msg":"start","GoID":1}`)
+	writeFile(t, filepath.Join(dir, "app-2022-08-01T10-00-00.000.log"),
+		`{"level":"warn","ts":"2022-08-01 09:59:00","caller":"app/db.go:20","msg":"slow query","GoID":7,"user":{"id":"u1"}}`,
+		`not json`)
+	writeFile(t, file,
+		`{"level":"error","ts":"2022-08-01 10:01:00","logger":"db","caller":"app/db.go:30","msg":"query timeout","GoID":7,"user":{"id":"u1"},"stacktrace":"main.query\n\tapp/db.go:30"}`,
+		`{"level":"error","ts":"2022-08-01 10:02:00","caller":"app/db.go:30","msg":"query timeout","GoID":8,"user":{"id":"u2"}}`)
+
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"-json", file}, []string{"10:01:00", "10:02:00"}},
+		{[]string{"-json", "-backups", file}, []string{"08:59:00", "09:59:00", "not json", "10:01:00", "10:02:00"}},
+		{[]string{"-json", "-backups", "-level", "warn", "-goid", "7", "-field", "user.id=u1", file}, []string{"09:59:00", "10:01:00"}},
+		{[]string{"-json", "-backups", "-since", "2022-08-01 09:00:00", "-until", "2022-08-01 10:02:00", "-grep", "^query", file}, []string{"10:01:00"}},
+		{[]string{"-json", "-backups", "-n", "2", file}, []string{"10:01:00", "10:02:00"}},
+		{[]string{"-color", "never", "-goid", "7", file}, []string{
+			"2022-08-01 10:01:00 ERROR [db] app/db.go:30 query timeout GoID=7 user={\"id\":\"u1\"}",
+			"main.query",
+			"\tapp/db.go:30",
+		}},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		if err := run(c.args, &out); err != nil {
+			t.Fatalf("args:%v err:%v", c.args, err)
+		}
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != len(c.want) {
+			t.Fatalf("args:%v out:\n%s", c.args, out.String())
+		}
+		for i, want := range c.want {
+			if !strings.Contains(lines[i], want) {
+				t.Fatalf("args:%v line %d:%q want:%q", c.args, i, lines[i], want)
+			}
+		}
+	}
+
+	if err := run([]string{"-since", "yesterday", file}, &bytes.Buffer{}); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2022, 8, 1, 10, 0, 0, 0, time.Local)
+	for _, raw := range []string{
+		`"2022-08-01 10:00:00"`,
+		`"` + want.Format(time.RFC3339Nano) + `"`,
+		strconv.FormatInt(want.UnixNano()/int64(time.Millisecond), 10),
+	} {
+		if got := parseTime([]byte(raw), "2006-01-02 15:04:05"); !got.Equal(want) {
+			t.Fatalf("raw:%s got:%v", raw, got)
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	followInterval = 10 * time.Millisecond
+	file := filepath.Join(t.TempDir(), "app.log")
+	writeFile(t, file, "old")
+
+	var (
+		mu    sync.Mutex
+		lines []string
+	)
+	done := make(chan struct{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- follow(file, done, func(line []byte) {
+			mu.Lock()
+			lines = append(lines, strings.TrimSpace(string(line)))
+			mu.Unlock()
+		})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("a\nb")
+	time.Sleep(50 * time.Millisecond)
+	_, _ = f.WriteString("c\nlast\n")
+	_ = f.Close()
+	// 切割后写入新文件
+	if err := os.Rename(file, file+".1"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, file, "new")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		got := strings.Join(lines, ",")
+		mu.Unlock()
+		if got == "a,bc,last,new" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("lines:%s", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(done)
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// 终端颜色，与本地调试时zap的带颜色级别一致
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorFaint   = "\x1b[2m"
+)
+
+func levelColor(lvl zapcore.Level) string {
+	switch {
+	case lvl <= zapcore.DebugLevel:
+		return colorMagenta
+	case lvl == zapcore.InfoLevel:
+		return colorBlue
+	case lvl == zapcore.WarnLevel:
+		return colorYellow
+	default:
+		return colorRed
+	}
+}
+
+// printer 输出匹配的日志，tail大于0时先缓存，Flush时只输出最后tail条
+type printer struct {
+	w     *bufio.Writer
+	keys  *keys
+	raw   bool
+	color bool
+
+	tail int
+	ring []string
+	next int
+}
+
+func newPrinter(w io.Writer, k *keys, raw, color bool, tail int) *printer {
+	return &printer{w: bufio.NewWriter(w), keys: k, raw: raw, color: color, tail: tail}
+}
+
+// Print 输出一条日志，e为nil时原样输出line
+func (p *printer) Print(e *entry, line []byte) {
+	var s string
+	if e == nil || p.raw {
+		s = strings.TrimRight(string(line), "\r\n")
+	} else {
+		s = p.format(e)
+	}
+
+	if p.tail <= 0 {
+		p.w.WriteString(s)
+		p.w.WriteByte('\n')
+		return
+	}
+	if len(p.ring) < p.tail {
+		p.ring = append(p.ring, s)
+		return
+	}
+	p.ring[p.next] = s
+	p.next = (p.next + 1) % p.tail
+}
+
+// Flush 输出缓存的日志，之后不再缓存，用于follow时直接输出新日志
+func (p *printer) Flush() error {
+	for i := range p.ring {
+		p.w.WriteString(p.ring[(p.next+i)%len(p.ring)])
+		p.w.WriteByte('\n')
+	}
+	p.ring, p.next, p.tail = nil, 0, 0
+	return p.w.Flush()
+}
+
+// format 时间 级别 [logger] 调用位置 消息 key=value...，调用栈另起一行
+func (p *printer) format(e *entry) string {
+	var b strings.Builder
+
+	ts := e.str(p.keys.time)
+	if len(e.values[p.keys.time]) > 0 && e.values[p.keys.time][0] != '"' && !e.time.IsZero() {
+		ts = e.time.Format(util.TimeFormatDefault + ".000")
+	}
+	if ts != "" {
+		b.WriteString(ts)
+		b.WriteByte(' ')
+	}
+
+	level := fmt.Sprintf("%-5s", strings.ToUpper(e.level.String()))
+	if p.color {
+		level = levelColor(e.level) + level + colorReset
+	}
+	b.WriteString(level)
+
+	if name := e.str(p.keys.logger); name != "" {
+		b.WriteString(" [" + name + "]")
+	}
+	if caller := e.str(p.keys.caller); caller != "" {
+		b.WriteString(" " + p.faint(caller))
+	}
+	b.WriteString(" " + e.message)
+
+	for _, name := range e.names {
+		switch name {
+		case p.keys.time, p.keys.level, p.keys.logger, p.keys.caller, p.keys.message, p.keys.stack:
+			continue
+		}
+		b.WriteString(" " + p.faint(name+"=") + e.str(name))
+	}
+
+	if stack := e.str(p.keys.stack); stack != "" {
+		b.WriteString("\n" + stack)
+	}
+	return b.String()
+}
+
+func (p *printer) faint(s string) string {
+	if !p.color {
+		return s
+	}
+	return colorFaint + s + colorReset
+}
@@ -0,0 +1,225 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v2"
+
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// FileConfig 日志配置文件，.json 后缀按json解析，其它按yaml解析，如
+//
+//	level: info
+//	moduleLevels:
+//	  db: debug
+//	sampling:
+//	  - {level: info, first: 100, thereafter: 10}
+//	rateLimit:
+//	  - {level: warn, limit: 10, interval: 1m}
+//	outputs:
+//	  - {name: error, level: warn}
+//	redact:
+//	  keys: [card]
+//	  patterns: ['card_no=(\d+)']
+//
+// 未配置的项保持代码中的设置
+type FileConfig struct {
+	Level           string            `json:"level" yaml:"level"`
+	ModuleLevels    map[string]string `json:"moduleLevels" yaml:"moduleLevels"`
+	Sampling        []SamplingConfig  `json:"sampling" yaml:"sampling"`
+	RateLimit       []RateLimitConfig `json:"rateLimit" yaml:"rateLimit"`
+	SummaryInterval string            `json:"summaryInterval" yaml:"summaryInterval"`
+	Outputs         []OutputConfig    `json:"outputs" yaml:"outputs"`
+	Redact          RedactConfig      `json:"redact" yaml:"redact"`
+}
+
+// SamplingConfig 见 util.Sampling
+type SamplingConfig struct {
+	Level      string `json:"level" yaml:"level"`
+	First      int    `json:"first" yaml:"first"`
+	Thereafter int    `json:"thereafter" yaml:"thereafter"`
+}
+
+// RateLimitConfig 见 util.RateLimit，Interval 为 time.ParseDuration 的格式
+type RateLimitConfig struct {
+	Level    string `json:"level" yaml:"level"`
+	Limit    int    `json:"limit" yaml:"limit"`
+	Interval string `json:"interval" yaml:"interval"`
+}
+
+// OutputConfig 见 util.AddFileOutput
+type OutputConfig struct {
+	Name  string `json:"name" yaml:"name"`
+	Level string `json:"level" yaml:"level"`
+}
+
+// RedactConfig Keys、Patterns 在代码中的脱敏规则之外增加，Disable 为true时不脱敏
+type RedactConfig struct {
+	Disable  bool     `json:"disable" yaml:"disable"`
+	Keys     []string `json:"keys" yaml:"keys"`
+	Patterns []string `json:"patterns" yaml:"patterns"`
+}
+
+// LoadConfig 读取并校验配置文件，不认识的配置项视为错误
+func LoadConfig(path string) (*FileConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &FileConfig{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(config)
+	} else {
+		err = yaml.UnmarshalStrict(data, config)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", path)
+	}
+
+	if _, err = config.parse(); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", path)
+	}
+	return config, nil
+}
+
+// parsedConfig 校验后的配置
+type parsedConfig struct {
+	level        *zapcore.Level
+	moduleLevels map[string]zapcore.Level
+	options      []util.Option // 需要重新创建输出的配置
+	patterns     []*regexp.Regexp
+}
+
+func (c *FileConfig) parse() (*parsedConfig, error) {
+	p := &parsedConfig{moduleLevels: make(map[string]zapcore.Level, len(c.ModuleLevels))}
+
+	if c.Level != "" {
+		lvl, err := zapcore.ParseLevel(c.Level)
+		if err != nil {
+			return nil, err
+		}
+		p.level = &lvl
+	}
+	for module, text := range c.ModuleLevels {
+		lvl, err := zapcore.ParseLevel(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "module %s", module)
+		}
+		p.moduleLevels[module] = lvl
+	}
+
+	for _, s := range c.Sampling {
+		lvl, err := zapcore.ParseLevel(s.Level)
+		if err != nil {
+			return nil, errors.Wrap(err, "sampling")
+		}
+		if s.First < 0 || s.Thereafter < 0 {
+			return nil, errors.Errorf("sampling %s: negative first or thereafter", s.Level)
+		}
+		p.options = append(p.options, util.Sampling(lvl, s.First, s.Thereafter))
+	}
+	for _, r := range c.RateLimit {
+		lvl, err := zapcore.ParseLevel(r.Level)
+		if err != nil {
+			return nil, errors.Wrap(err, "rateLimit")
+		}
+		interval, err := time.ParseDuration(r.Interval)
+		if err != nil || interval <= 0 || r.Limit <= 0 {
+			return nil, errors.Errorf("rateLimit %s: invalid limit %d or interval %q", r.Level, r.Limit, r.Interval)
+		}
+		p.options = append(p.options, util.RateLimit(lvl, r.Limit, interval))
+	}
+	if c.SummaryInterval != "" {
+		interval, err := time.ParseDuration(c.SummaryInterval)
+		if err != nil {
+			return nil, errors.Wrap(err, "summaryInterval")
+		}
+		p.options = append(p.options, util.SuppressedSummaryInterval(interval))
+	}
+	for _, o := range c.Outputs {
+		lvl, err := zapcore.ParseLevel(o.Level)
+		if err != nil {
+			return nil, errors.Wrapf(err, "output %s", o.Name)
+		}
+		if o.Name == "" || strings.ContainsAny(o.Name, `/\`) {
+			return nil, errors.Errorf("invalid output name %q", o.Name)
+		}
+		p.options = append(p.options, util.AddFileOutput(o.Name, lvl))
+	}
+
+	for _, pattern := range c.Redact.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "redact")
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	return p, nil
+}
+
+// needRebuild 采样、输出文件及是否脱敏需要重新创建输出，级别和脱敏规则可以直接修改
+func (c *FileConfig) needRebuild(old *FileConfig) bool {
+	return !reflect.DeepEqual(c.Sampling, old.Sampling) ||
+		!reflect.DeepEqual(c.RateLimit, old.RateLimit) ||
+		c.SummaryInterval != old.SummaryInterval ||
+		!reflect.DeepEqual(c.Outputs, old.Outputs) ||
+		c.Redact.Disable != old.Redact.Disable
+}
+
+// diff 与old相比修改的配置项，用于输出日志
+func (c *FileConfig) diff(old *FileConfig) []string {
+	var changes []string
+	add := func(name string, from, to interface{}) {
+		if !reflect.DeepEqual(from, to) && !(isEmpty(from) && isEmpty(to)) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, from, to))
+		}
+	}
+
+	add("level", old.Level, c.Level)
+	modules := make([]string, 0, len(c.ModuleLevels)+len(old.ModuleLevels))
+	for module := range c.ModuleLevels {
+		modules = append(modules, module)
+	}
+	for module := range old.ModuleLevels {
+		if _, ok := c.ModuleLevels[module]; !ok {
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		add("moduleLevels."+module, old.ModuleLevels[module], c.ModuleLevels[module])
+	}
+	add("sampling", old.Sampling, c.Sampling)
+	add("rateLimit", old.RateLimit, c.RateLimit)
+	add("summaryInterval", old.SummaryInterval, c.SummaryInterval)
+	add("outputs", old.Outputs, c.Outputs)
+	add("redact.disable", old.Redact.Disable, c.Redact.Disable)
+	add("redact.keys", old.Redact.Keys, c.Redact.Keys)
+	add("redact.patterns", old.Redact.Patterns, c.Redact.Patterns)
+	return changes
+}
+
+// isEmpty nil与长度为0的切片、map视为相同
+func isEmpty(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package plog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+)
+
+type ctxFieldsKey struct{}
+
+// ContextHook 通过 Ctx 获取Logger时按ctx包装core，返回core.With(fields)可以附加ctx中的字段，
+// 也可以返回自定义的core在写日志时做额外处理，如 otelplog 附加trace_id并记录span事件
+type ContextHook func(ctx context.Context, core zapcore.Core) zapcore.Core
+
+var (
+	contextHooksMu sync.Mutex
+	contextHooks   atomic.Value // []ContextHook
+)
+
+// RegisterContextHook 注册ContextHook，对之后的 Ctx 调用及slog日志生效，通常在初始化时调用
+func RegisterContextHook(hook ContextHook) {
+	contextHooksMu.Lock()
+	defer contextHooksMu.Unlock()
+
+	hooks, _ := contextHooks.Load().([]ContextHook)
+	merged := make([]ContextHook, 0, len(hooks)+1)
+	merged = append(merged, hooks...)
+	contextHooks.Store(append(merged, hook))
+}
+
+// contextCore 按ctx包装core，依次应用请求级缓冲和注册的ContextHook
+func contextCore(ctx context.Context, core zapcore.Core) zapcore.Core {
+	if buffer := contextBuffer(ctx); buffer != nil {
+		core = filter.NewBufferCore(core, buffer)
+	}
+	hooks, _ := contextHooks.Load().([]ContextHook)
+	for _, hook := range hooks {
+		core = hook(ctx, core)
+	}
+	return core
+}
+
+// contextLogger 返回按ctx包装了core的zap.Logger，不需要包装时原样返回
+func contextLogger(ctx context.Context, zl *zap.Logger) *zap.Logger {
+	hooks, _ := contextHooks.Load().([]ContextHook)
+	if zl == nil || ctx == nil || (len(hooks) == 0 && contextBuffer(ctx) == nil) {
+		return zl
+	}
+	return zl.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return contextCore(ctx, core)
+	}))
+}
+
+// WithContext 将fields保存到ctx中并返回新的ctx，通过 Ctx 从该ctx及其派生的ctx
+// 获取的Logger都会自动带上这些字段，如 util.TraceID、util.SpanID、util.UserID
+func WithContext(ctx context.Context, fields ...zapcore.Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	parent := ContextFields(ctx)
+	merged := make([]zapcore.Field, 0, len(parent)+len(fields))
+	merged = append(merged, parent...)
+	for _, field := range fields {
+		// 同名字段以新值为准，避免输出重复的key
+		replaced := false
+		for i := range merged {
+			if merged[i].Key == field.Key {
+				merged[i] = field
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, field)
+		}
+	}
+	return context.WithValue(ctx, ctxFieldsKey{}, merged)
+}
+
+// ContextFields 返回通过 WithContext 保存在ctx中的字段
+func ContextFields(ctx context.Context) []zapcore.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(ctxFieldsKey{}).([]zapcore.Field)
+	return fields
+}
+
+// Ctx 返回带有ctx中字段的Logger，用法 plog.Ctx(ctx).Info("msg", fields...)
+func Ctx(ctx context.Context) *Logger {
+	return std.Ctx(ctx)
+}
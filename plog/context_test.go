@@ -0,0 +1,65 @@
+package plog
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+func TestCtx(t *testing.T) {
+	logs := NewTestLogger(t)
+
+	ctx := WithContext(context.Background(), util.TraceID("t1"), util.UserID("u1"))
+	ctx = WithContext(ctx, util.SpanID("s1"), util.UserID("u2"))
+	Ctx(ctx).Info("ctx", zap.Int("n", 1))
+	Ctx(context.Background()).Info("empty")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("entries:%v", entries)
+	}
+	fields := entries[0].ContextMap()
+	if fields["TraceID"] != "t1" || fields["SpanID"] != "s1" || fields["UserID"] != "u2" ||
+		fields["n"] != int64(1) || fields["GoID"] == nil {
+		t.Fatalf("fields:%v", fields)
+	}
+	if len(entries[1].Context) != 1 {
+		t.Fatalf("fields:%v", entries[1].Context)
+	}
+}
+
+func TestContextHook(t *testing.T) {
+	logs := NewTestLogger(t)
+
+	hooks := contextHooks.Load()
+	defer func() {
+		if hooks != nil {
+			contextHooks.Store(hooks)
+		} else {
+			contextHooks.Store([]ContextHook(nil))
+		}
+	}()
+
+	type key struct{}
+	RegisterContextHook(func(ctx context.Context, core zapcore.Core) zapcore.Core {
+		if v, ok := ctx.Value(key{}).(string); ok {
+			return core.With([]zapcore.Field{zap.String("hook", v)})
+		}
+		return core
+	})
+
+	Ctx(context.WithValue(context.Background(), key{}, "v")).Info("hooked")
+	Ctx(context.Background()).Info("plain")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 || entries[0].ContextMap()["hook"] != "v" {
+		t.Fatalf("entries:%v", entries)
+	}
+	if _, ok := entries[1].ContextMap()["hook"]; ok {
+		t.Fatalf("fields:%v", entries[1].ContextMap())
+	}
+}
@@ -0,0 +1,148 @@
+package plog
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 错误链和多错误嵌套的最大展开层数，避免异常的错误类型导致死循环
+const (
+	maxErrChain = 32
+	maxErrDepth = 8
+)
+
+type (
+	wrapper interface {
+		Unwrap() error
+	}
+	causer interface {
+		Cause() error
+	}
+	multiWrapper interface {
+		Unwrap() []error
+	}
+	stackTracer interface {
+		StackTrace() errors.StackTrace
+	}
+)
+
+// Err 以error为key输出错误，包括完整的错误链、最内层 pkg/errors 的调用栈，
+// 以及 errors.Join、bw.ErrorList 等多错误中的每个错误。err为nil时不输出
+//
+//	{"error":{"msg":"query: timeout","causes":["query: timeout","timeout"],"stack":"..."}}
+func Err(err error) zapcore.Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr 与 Err 相同，使用指定的key
+func NamedErr(key string, err error) zapcore.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, errObject{err: err})
+}
+
+// errObject 输出为 {msg, causes, stack, errors}。
+// 实现 filter.StringMapper，脱敏和截断在输出时作用于其中的每个字符串
+type errObject struct {
+	err    error
+	depth  int
+	mapper func(string) string
+}
+
+// MapStrings ...
+func (e errObject) MapStrings(f func(string) string) zapcore.ObjectMarshaler {
+	if prev := e.mapper; prev != nil {
+		e.mapper = func(s string) string { return f(prev(s)) }
+	} else {
+		e.mapper = f
+	}
+	return e
+}
+
+func (e errObject) mapString(s string) string {
+	if e.mapper == nil {
+		return s
+	}
+	return e.mapper(s)
+}
+
+// MarshalLogObject ...
+func (e errObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("msg", e.mapString(e.err.Error()))
+
+	var (
+		causes errCauses
+		stack  stackTracer
+		multi  []error
+	)
+	for err, i := e.err, 0; err != nil && i < maxErrChain; i++ {
+		// pkg/errors 的 withStack 等与被包装的错误文本相同，只记录一次
+		if msg := err.Error(); len(causes) == 0 || causes[len(causes)-1] != msg {
+			causes = append(causes, msg)
+		}
+		if st, ok := err.(stackTracer); ok {
+			stack = st
+		}
+		err, multi = unwrapErr(err)
+	}
+
+	for i := range causes {
+		causes[i] = e.mapString(causes[i])
+	}
+	if err := enc.AddArray("causes", causes); err != nil {
+		return err
+	}
+	if stack != nil {
+		enc.AddString("stack", e.mapString(fmt.Sprintf("%+v", stack.StackTrace())))
+	}
+	if len(multi) > 0 && e.depth < maxErrDepth {
+		return enc.AddArray("errors", errList{errs: multi, depth: e.depth + 1, mapper: e.mapper})
+	}
+	return nil
+}
+
+// unwrapErr 返回被包装的错误，多错误时返回其中的所有错误
+func unwrapErr(err error) (error, []error) {
+	switch e := err.(type) {
+	case multiWrapper:
+		return nil, e.Unwrap()
+	case wrapper:
+		return e.Unwrap(), nil
+	case causer:
+		return e.Cause(), nil
+	}
+	return nil, nil
+}
+
+type errCauses []string
+
+// MarshalLogArray ...
+func (c errCauses) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, msg := range c {
+		enc.AppendString(msg)
+	}
+	return nil
+}
+
+type errList struct {
+	errs   []error
+	depth  int
+	mapper func(string) string
+}
+
+// MarshalLogArray ...
+func (l errList) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range l.errs {
+		if err == nil {
+			continue
+		}
+		if e := enc.AppendObject(errObject{err: err, depth: l.depth, mapper: l.mapper}); e != nil {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package plog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/sink"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// joined 与 errors.Join 的结果一样实现 Unwrap() []error
+type joined []error
+
+func (j joined) Error() string {
+	return "joined"
+}
+
+func (j joined) Unwrap() []error {
+	return j
+}
+
+func TestErr(t *testing.T) {
+	logs := NewTestLogger(t)
+
+	inner := errors.New("timeout")
+	Error("wrapped", Err(errors.Wrap(inner, "query")))
+	Error("multi", Err(errors.WithMessage(joined{inner, errors.Errorf("refused")}, "batch")))
+	Error("nil", Err(nil))
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("entries:%v", entries)
+	}
+
+	e := entries[0].ContextMap()["error"].(map[string]interface{})
+	causes := e["causes"].([]interface{})
+	if e["msg"] != "query: timeout" || len(causes) != 2 || causes[1] != "timeout" {
+		t.Fatalf("error:%v", e)
+	}
+	// 调用栈来自最内层的 errors.New
+	if stack, _ := e["stack"].(string); !strings.Contains(stack, "TestErr") {
+		t.Fatalf("stack:%v", e["stack"])
+	}
+
+	e = entries[1].ContextMap()["error"].(map[string]interface{})
+	errs := e["errors"].([]interface{})
+	if e["msg"] != "batch: joined" || len(errs) != 2 {
+		t.Fatalf("error:%v", e)
+	}
+	if sub := errs[1].(map[string]interface{}); sub["msg"] != "refused" || sub["stack"] == nil {
+		t.Fatalf("sub:%v", sub)
+	}
+
+	if _, ok := entries[2].ContextMap()["error"]; ok {
+		t.Fatalf("nil error:%v", entries[2].ContextMap())
+	}
+}
+
+func TestErrRedactTruncate(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := initTestLog(t, util.MaxMsgSize(64), util.AddSink(sink.NewWriterSink(&buf), zapcore.InfoLevel)); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := errors.New("user phone: 13812345678 " + strings.Repeat("x", 100))
+	Error("failed", Err(joined{errors.Wrap(inner, "query"), errors.New("mobile=13912345678")}))
+	_ = Sync()
+
+	out := buf.String()
+	if strings.Contains(out, "13812345678") || strings.Contains(out, "13912345678") {
+		t.Fatalf("not redacted:%s", out)
+	}
+	type errJSON struct {
+		Msg    string    `json:"msg"`
+		Causes []string  `json:"causes"`
+		Stack  string    `json:"stack"`
+		Errors []errJSON `json:"errors"`
+	}
+	var line struct {
+		Error errJSON `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil || len(line.Error.Errors) != 2 {
+		t.Fatalf("output:%s err:%v", out, err)
+	}
+	e := line.Error.Errors[0]
+	if !strings.Contains(e.Causes[1], "phone: ***") || !strings.Contains(e.Causes[1], "original size:") ||
+		len(e.Causes[1]) > 128 || !strings.Contains(e.Stack, "original size:") ||
+		line.Error.Errors[1].Msg != "mobile=***" {
+		t.Fatalf("error:%+v", e)
+	}
+}
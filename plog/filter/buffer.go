@@ -0,0 +1,332 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBufferEntries = 256
+	defaultBufferBytes   = 256 * 1024
+	// 估算单条日志内存时每条和每个字段的固定开销
+	bufferEntryOverhead = 128
+	bufferFieldOverhead = 64
+	bufferDropMessage   = "plog dropped buffered entries"
+)
+
+var (
+	// snapshotEncoder 暂存时编码延迟求值的字段，只输出字段
+	snapshotEncoder = zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		EncodeTime:     zapcore.EpochTimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+	})
+	// sizeEncoder 按编码后的长度计算暂存日志的大小
+	sizeEncoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+)
+
+// BufferConfig 请求级缓冲配置，零值字段使用默认值
+type BufferConfig struct {
+	// MinLevel 全局级别之下、不低于MinLevel的日志会暂存，为nil时默认Debug
+	MinLevel zapcore.LevelEnabler
+	// TriggerLevel 输出该级别的日志时先把暂存的日志写出，为nil时默认Error及以上
+	TriggerLevel zapcore.LevelEnabler
+	// MaxEntries 最多暂存的条数，超出时丢弃最早的，默认256
+	MaxEntries int
+	// MaxBytes 暂存日志的估算内存上限，超出时丢弃最早的，默认256KB
+	MaxBytes int
+}
+
+type bufferedEntry struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+	size   int
+}
+
+// Buffer 一个请求范围内暂存的低级别日志，触发后写出，结束时丢弃。可以并发使用
+type Buffer struct {
+	config BufferConfig
+
+	mu        sync.Mutex
+	entries   []bufferedEntry
+	size      int
+	dropped   int
+	triggered bool // 已写出过，之后的日志直接输出
+	closed    bool // 已结束，之后的日志按全局级别处理
+}
+
+// NewBuffer ...
+func NewBuffer(config BufferConfig) *Buffer {
+	if config.MinLevel == nil {
+		config.MinLevel = zapcore.DebugLevel
+	}
+	if config.TriggerLevel == nil {
+		config.TriggerLevel = zapcore.ErrorLevel
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultBufferEntries
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaultBufferBytes
+	}
+	return &Buffer{config: config}
+}
+
+// Enabled 该级别的日志是否会被暂存或在触发后直接输出
+func (b *Buffer) Enabled(lvl zapcore.Level) bool {
+	if !b.config.MinLevel.Enabled(lvl) {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.closed
+}
+
+// Flush 写出暂存的日志，之后同一范围内的日志不再暂存而是直接输出。
+// 输出触发级别的日志时会自动调用
+func (b *Buffer) Flush() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	entries, dropped := b.entries, b.dropped
+	b.entries, b.size, b.dropped = nil, 0, 0
+	b.triggered = true
+	b.mu.Unlock()
+
+	if dropped > 0 && len(entries) > 0 {
+		entry := zapcore.Entry{
+			Level:   zapcore.WarnLevel,
+			Time:    entries[0].entry.Time,
+			Message: bufferDropMessage,
+		}
+		if ce := entries[0].core.Check(entry, nil); ce != nil {
+			ce.Write(zap.Int("dropped", dropped))
+		}
+	}
+	for _, e := range entries {
+		if ce := e.core.Check(e.entry, nil); ce != nil {
+			ce.Write(e.fields...)
+		}
+	}
+}
+
+// Close 丢弃暂存的日志，请求结束时调用
+func (b *Buffer) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries, b.size, b.dropped = nil, 0, 0
+	b.closed = true
+}
+
+// Len 暂存的条数
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Dropped 因超出上限被丢弃的条数
+func (b *Buffer) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// add 暂存一条日志，已触发时返回true由调用方直接输出
+func (b *Buffer) add(core zapcore.Core, entry zapcore.Entry, fields []zapcore.Field) (passthrough bool) {
+	fields = snapshotFields(fields)
+	e := bufferedEntry{
+		core:   core,
+		entry:  entry,
+		fields: fields,
+		size:   bufferedSize(entry, fields),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return false
+	}
+	if b.triggered {
+		return true
+	}
+	if e.size > b.config.MaxBytes {
+		b.dropped++
+		return false
+	}
+
+	b.entries = append(b.entries, e)
+	b.size += e.size
+	n := 0
+	for len(b.entries)-n > b.config.MaxEntries || b.size > b.config.MaxBytes {
+		b.size -= b.entries[n].size
+		b.entries[n] = bufferedEntry{}
+		n++
+	}
+	if n > 0 {
+		b.dropped += n
+		b.entries = b.entries[n:]
+	}
+	return false
+}
+
+// snapshotFields 暂存时记录协程ID，并编码 zap.Any、zap.Object、zap.Stringer 等
+// 写出时才求值的字段，写出的是暂存时的值。基本类型的字段原样保留
+func snapshotFields(fields []zapcore.Field) []zapcore.Field {
+	ret := make([]zapcore.Field, 0, len(fields)+1)
+	ret = append(ret, bufferedGoID(CurrentGoID()))
+	for _, f := range fields {
+		switch f.Type {
+		case zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType,
+			zapcore.ReflectType, zapcore.StringerType, zapcore.ErrorType:
+			ret = appendSnapshot(ret, f)
+		default:
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
+// appendSnapshot 编码为json后按原顺序解析为字段，错误等字段可能对应多个key
+func appendSnapshot(fields []zapcore.Field, f zapcore.Field) []zapcore.Field {
+	buf, err := snapshotEncoder.EncodeEntry(zapcore.Entry{}, []zapcore.Field{f})
+	if err != nil {
+		return append(fields, f)
+	}
+	defer buf.Free()
+
+	decoder := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	decoder.UseNumber()
+	if _, err = decoder.Token(); err != nil {
+		return append(fields, f)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		key, _ := token.(string)
+		var value interface{}
+		if err = decoder.Decode(&value); err != nil {
+			break
+		}
+		if s, ok := value.(string); ok {
+			fields = append(fields, zap.String(key, s))
+		} else {
+			fields = append(fields, zap.Reflect(key, value))
+		}
+	}
+	return fields
+}
+
+// bufferedSize 暂存一条日志占用的内存，按json编码后的长度加上固定开销计算
+func bufferedSize(entry zapcore.Entry, fields []zapcore.Field) int {
+	size := bufferEntryOverhead + bufferFieldOverhead*len(fields)
+	buf, err := sizeEncoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return size + len(entry.Message) + len(entry.Stack)
+	}
+	size += buf.Len()
+	buf.Free()
+	return size
+}
+
+// BufferCore 将全局级别之下的日志暂存到Buffer，输出触发级别的日志时先写出暂存的日志。
+// core为 LevelCore 时绕过其级别判断，这样暂存的日志写出时不会被过滤
+type BufferCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+	buffer  *Buffer
+}
+
+// NewBufferCore ...
+func NewBufferCore(core zapcore.Core, buffer *Buffer) *BufferCore {
+	var enabler zapcore.LevelEnabler = core
+	if lc, ok := core.(*LevelCore); ok {
+		core, enabler = lc.Unwrap(), lc.enabler
+	}
+	return &BufferCore{
+		Core:    core,
+		enabler: enabler,
+		buffer:  buffer,
+	}
+}
+
+// Enabled ...
+func (c *BufferCore) Enabled(lvl zapcore.Level) bool {
+	return c.enabler.Enabled(lvl) || c.buffer.Enabled(lvl)
+}
+
+// With ...
+func (c *BufferCore) With(fields []zapcore.Field) zapcore.Core {
+	return &BufferCore{
+		Core:    c.Core.With(fields),
+		enabler: c.enabler,
+		buffer:  c.buffer,
+	}
+}
+
+// Check ...
+func (c *BufferCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.buffer.config.TriggerLevel.Enabled(entry.Level) {
+		// 先于内层core加入，写这条日志之前先写出暂存的日志
+		checked = checked.AddCore(entry, bufferFlusher{buffer: c.buffer})
+	}
+	if c.enabler.Enabled(entry.Level) {
+		return c.Core.Check(entry, checked)
+	}
+	if c.buffer.Enabled(entry.Level) {
+		// caller和调用栈在Check之后才会填充，在Write里暂存
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write 只有需要暂存的日志会走到这里
+func (c *BufferCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.buffer.add(c.Core, entry, fields) {
+		return nil
+	}
+	if ce := c.Core.Check(entry, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+// bufferFlusher 只在Check中临时使用，Write时写出暂存的日志
+type bufferFlusher struct {
+	buffer *Buffer
+}
+
+// Enabled ...
+func (f bufferFlusher) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With ...
+func (f bufferFlusher) With([]zapcore.Field) zapcore.Core {
+	return f
+}
+
+// Check ...
+func (f bufferFlusher) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, f)
+}
+
+// Write ...
+func (f bufferFlusher) Write(zapcore.Entry, []zapcore.Field) error {
+	f.buffer.Flush()
+	return nil
+}
+
+// Sync ...
+func (f bufferFlusher) Sync() error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"os"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 内层写入失败时与zap默认的ErrorOutput一样输出到stderr
+var checkedErrorOutput = zapcore.Lock(os.Stderr)
+
+// entryWriter Write的下一层，可以是内层core，也可以是内层Check的结果
+type entryWriter interface {
+	Write(zapcore.Entry, []zapcore.Field) error
+}
+
+// fieldsCore 修改日志内容后再写入下一层的core，如脱敏、截断、GoID
+type fieldsCore interface {
+	zapcore.Core
+	writeTo(next entryWriter, entry zapcore.Entry, fields []zapcore.Field) error
+}
+
+// checkWrapped 先调用内层core的Check，内层会写入时才把core加入checked，
+// Write时由core修改后写入内层Check的结果，这样内层的级别、采样等判断不会被绕过
+func checkWrapped(core fieldsCore, inner zapcore.Core, entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce := inner.Check(entry, nil)
+	if ce == nil {
+		return checked
+	}
+	w := checkedWriterPool.Get().(*checkedWriter)
+	w.fieldsCore, w.ce = core, ce
+	return checked.AddCore(entry, w)
+}
+
+var checkedWriterPool = sync.Pool{
+	New: func() interface{} {
+		return &checkedWriter{}
+	},
+}
+
+// checkedWriter 一次Check的结果，Write之后放回
+type checkedWriter struct {
+	fieldsCore
+	ce *zapcore.CheckedEntry
+}
+
+// Write ...
+func (w *checkedWriter) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	core, ce := w.fieldsCore, w.ce
+	w.fieldsCore, w.ce = nil, nil
+	checkedWriterPool.Put(w)
+	return core.writeTo(checkedEntry{ce: ce}, entry, fields)
+}
+
+// checkedEntry 将内层Check的结果作为下一层写入
+type checkedEntry struct {
+	ce *zapcore.CheckedEntry
+}
+
+// Write ...
+func (e checkedEntry) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	// caller和调用栈在Check之后才填充，消息可能已被修改
+	e.ce.Entry = entry
+	e.ce.ErrorOutput = checkedErrorOutput
+	e.ce.Write(fields...)
+	return nil
+}
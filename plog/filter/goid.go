@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// GoIDKey 协程ID字段的key
+const GoIDKey = "GoID"
+
+// 复用带GoID的字段切片，避免每次打日志都分配
+var goIDFieldsPool = sync.Pool{
+	New: func() interface{} {
+		fields := make([]zapcore.Field, 0, 16)
+		return &fields
+	},
+}
+
+// GoIDCore 在Write时加上当前协程ID字段。
+// zap在打日志的协程中同步调用Write，所以能拿到调用方的协程ID。
+// 包在所有输出组合成的core外面，每条日志只取一次协程ID，各输出共用带GoID的字段切片
+type GoIDCore struct {
+	zapcore.Core
+}
+
+// NewGoIDCore ...
+func NewGoIDCore(core zapcore.Core) zapcore.Core {
+	return &GoIDCore{Core: core}
+}
+
+// With ...
+func (c *GoIDCore) With(fields []zapcore.Field) zapcore.Core {
+	return &GoIDCore{Core: c.Core.With(fields)}
+}
+
+// Check ...
+func (c *GoIDCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checkWrapped(c, c.Core, entry, checked)
+}
+
+// Write GoID放在第一个字段，与调用处的字段一起写入
+func (c *GoIDCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.writeTo(c.Core, entry, fields)
+}
+
+func (c *GoIDCore) writeTo(next entryWriter, entry zapcore.Entry, fields []zapcore.Field) error {
+	id, fields := goIDOf(fields)
+	p := goIDFieldsPool.Get().(*[]zapcore.Field)
+	withID := append((*p)[:0], zapcore.Field{Key: GoIDKey, Type: zapcore.Int64Type, Integer: id})
+	withID = append(withID, fields...)
+
+	err := next.Write(entry, withID)
+
+	// 清掉引用后放回，下游不会在Write之后持有字段切片
+	for i := range withID {
+		withID[i] = zapcore.Field{}
+	}
+	*p = withID[:0]
+	goIDFieldsPool.Put(p)
+	return err
+}
+
+// bufferedGoID 暂存日志时记录的协程ID，编码器不会输出SkipType的字段，
+// 写出时由 GoIDCore 转为GoID字段，没有 GoIDCore 时忽略
+func bufferedGoID(id int64) zapcore.Field {
+	return zapcore.Field{Key: GoIDKey, Type: zapcore.SkipType, Integer: id}
+}
+
+// goIDOf 第一个字段是 bufferedGoID 时使用记录的协程ID，否则为当前协程ID
+func goIDOf(fields []zapcore.Field) (int64, []zapcore.Field) {
+	if len(fields) > 0 && fields[0].Key == GoIDKey && fields[0].Type == zapcore.SkipType {
+		return fields[0].Integer, fields[1:]
+	}
+	return CurrentGoID(), fields
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+var stackBufPool = sync.Pool{
+	New: func() interface{} {
+		return new([64]byte)
+	},
+}
+
+// stackGoID 从 runtime.Stack 第一行的"goroutine 18 [running]:"解析协程ID。
+// 不依赖runtime内部结构，但要遍历整个调用栈，每次需要几微秒，并且随调用栈深度增加
+func stackGoID() int64 {
+	buf := stackBufPool.Get().(*[64]byte)
+	defer stackBufPool.Put(buf)
+
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], goroutinePrefix)
+	var id int64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + int64(c-'0')
+	}
+	return id
+}
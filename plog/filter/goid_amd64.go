@@ -0,0 +1,18 @@
+package filter
+
+import (
+	"github.com/v2pro/plz/gls"
+)
+
+// gls.GoID 按runtime.g中goid字段的偏移直接读取，只要几纳秒，而 stackGoID 要几微秒，
+// 所以在amd64上继续使用（gls其他平台的汇编已无法用新版本Go编译）。启动时与 stackGoID 比对一次，
+// runtime结构变化导致读到的值不对时退回 stackGoID
+var glsGoIDValid = gls.GoID() == stackGoID()
+
+// CurrentGoID 当前协程ID
+func CurrentGoID() int64 {
+	if glsGoIDValid {
+		return gls.GoID()
+	}
+	return stackGoID()
+}
@@ -0,0 +1,9 @@
+//go:build !amd64
+// +build !amd64
+
+package filter
+
+// CurrentGoID 当前协程ID。gls只能在amd64上使用，其他平台解析调用栈
+func CurrentGoID() int64 {
+	return stackGoID()
+}
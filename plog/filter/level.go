@@ -0,0 +1,90 @@
+package filter
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelCore 在core外层按级别过滤。内层core只负责输出，
+// 全局级别和模块级别都在这一层判断，因此模块级别可以低于全局级别
+type LevelCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+}
+
+// NewLevelCore ...
+func NewLevelCore(core zapcore.Core, enabler zapcore.LevelEnabler) *LevelCore {
+	return &LevelCore{
+		Core:    core,
+		enabler: enabler,
+	}
+}
+
+// Enabled ...
+func (c *LevelCore) Enabled(lvl zapcore.Level) bool {
+	return c.enabler.Enabled(lvl)
+}
+
+// With ...
+func (c *LevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &LevelCore{
+		Core:    c.Core.With(fields),
+		enabler: c.enabler,
+	}
+}
+
+// Check ...
+func (c *LevelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabler.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// Unwrap 返回内层core
+func (c *LevelCore) Unwrap() zapcore.Core {
+	return c.Core
+}
+
+// ModuleLevel 模块独立的日志级别，未设置时沿用全局级别。可以并发使用
+type ModuleLevel struct {
+	level int32
+	set   int32
+}
+
+// SetLevel 设置模块级别
+func (m *ModuleLevel) SetLevel(lvl zapcore.Level) {
+	atomic.StoreInt32(&m.level, int32(lvl))
+	atomic.StoreInt32(&m.set, 1)
+}
+
+// Reset 恢复为沿用全局级别
+func (m *ModuleLevel) Reset() {
+	atomic.StoreInt32(&m.set, 0)
+}
+
+// Level 返回模块级别，未设置时ok为false
+func (m *ModuleLevel) Level() (lvl zapcore.Level, ok bool) {
+	if atomic.LoadInt32(&m.set) == 0 {
+		return lvl, false
+	}
+	return zapcore.Level(atomic.LoadInt32(&m.level)), true
+}
+
+// NewModuleCore 用模块级别替换core的全局级别判断。
+// core为 LevelCore 时绕过其全局级别，模块未设置级别时再回退到全局级别
+func NewModuleCore(core zapcore.Core, module *ModuleLevel) zapcore.Core {
+	var global zapcore.LevelEnabler = core
+	if lc, ok := core.(*LevelCore); ok {
+		core, global = lc.Unwrap(), lc.enabler
+	}
+
+	return NewLevelCore(core, zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		if moduleLvl, ok := module.Level(); ok {
+			return lvl >= moduleLvl
+		}
+		return global.Enabled(lvl)
+	}))
+}
@@ -0,0 +1,246 @@
+package filter
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSummaryInterval = 10 * time.Second
+	summaryMessage         = "plog suppressed log entries"
+)
+
+// SamplingPolicy 与zap的采样相同，每个Tick内同一消息先输出First条，之后每Thereafter条输出一条
+type SamplingPolicy struct {
+	Tick       time.Duration
+	First      int
+	Thereafter int
+}
+
+// RateLimitPolicy 同一调用位置的同一消息每个Interval内最多输出Limit条
+type RateLimitPolicy struct {
+	Interval time.Duration
+	Limit    int
+}
+
+// SampleConfig 按级别配置的采样和限流
+type SampleConfig struct {
+	Sampling  map[zapcore.Level]SamplingPolicy
+	RateLimit map[zapcore.Level]RateLimitPolicy
+	// SummaryInterval 输出被丢弃条数汇总的间隔，默认10秒。
+	// 第一次丢弃日志时启动定时协程输出汇总，Close时停止；Sync和Close会立即输出未汇总的条数
+	SummaryInterval time.Duration
+}
+
+type limitKey struct {
+	message string
+	file    string
+	line    int
+}
+
+// rateLimiter 固定窗口计数，窗口结束时清空，内存占用与一个窗口内的调用位置数相关
+type rateLimiter struct {
+	policy      RateLimitPolicy
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[limitKey]int
+}
+
+func (l *rateLimiter) allow(entry zapcore.Entry) bool {
+	key := limitKey{
+		message: entry.Message,
+		file:    entry.Caller.File,
+		line:    entry.Caller.Line,
+	}
+
+	// slog没有时间的日志为零值，按当前时间计算窗口
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.Time.Sub(l.windowStart) >= l.policy.Interval || entry.Time.Before(l.windowStart) {
+		l.windowStart = entry.Time
+		l.counts = make(map[limitKey]int, len(l.counts))
+	}
+	l.counts[key]++
+	return l.counts[key] <= l.policy.Limit
+}
+
+// sampleState 在With产生的core之间共享
+type sampleState struct {
+	root        zapcore.Core
+	limiters    map[zapcore.Level]*rateLimiter
+	interval    time.Duration
+	sampled     int64
+	rateLimited int64
+	start       sync.Once
+	stop        sync.Once
+	done        chan struct{}
+}
+
+// dropped 记录一条被丢弃的日志，第一次丢弃时启动汇总协程
+func (s *sampleState) dropped(counter *int64) {
+	atomic.AddInt64(counter, 1)
+	s.start.Do(func() {
+		select {
+		case <-s.done:
+			// 已经关闭，只在Sync时汇总
+		default:
+			go s.run()
+		}
+	})
+}
+
+func (s *sampleState) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.summary(now)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *sampleState) close() {
+	s.stop.Do(func() { close(s.done) })
+	s.summary(time.Now())
+}
+
+// summary 输出并清零被丢弃的条数，没有丢弃时不输出
+func (s *sampleState) summary(now time.Time) {
+	sampled := atomic.SwapInt64(&s.sampled, 0)
+	rateLimited := atomic.SwapInt64(&s.rateLimited, 0)
+	if sampled == 0 && rateLimited == 0 {
+		return
+	}
+
+	entry := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    now,
+		Message: summaryMessage,
+	}
+	if ce := s.root.Check(entry, nil); ce != nil {
+		ce.Write(zap.Int64("sampled", sampled), zap.Int64("rateLimited", rateLimited))
+	}
+}
+
+// SampleCore 按级别采样和限流，并定期输出被丢弃的条数，不再使用时需要Close停止汇总协程。
+// 限流需要用到caller，而caller在Check之后才会填充，因此限流放在Write里做
+type SampleCore struct {
+	zapcore.Core
+	samplers map[zapcore.Level]zapcore.Core
+	state    *sampleState
+}
+
+// NewSampleCore 没有任何配置时直接返回core
+func NewSampleCore(core zapcore.Core, config SampleConfig) zapcore.Core {
+	if len(config.Sampling) == 0 && len(config.RateLimit) == 0 {
+		return core
+	}
+
+	state := &sampleState{
+		root:     core,
+		limiters: make(map[zapcore.Level]*rateLimiter, len(config.RateLimit)),
+		interval: config.SummaryInterval,
+		done:     make(chan struct{}),
+	}
+	if state.interval <= 0 {
+		state.interval = defaultSummaryInterval
+	}
+
+	for lvl, policy := range config.RateLimit {
+		state.limiters[lvl] = &rateLimiter{policy: policy}
+	}
+
+	hook := zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped > 0 {
+			state.dropped(&state.sampled)
+		}
+	})
+	samplers := make(map[zapcore.Level]zapcore.Core, len(config.Sampling))
+	for lvl, policy := range config.Sampling {
+		tick := policy.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		samplers[lvl] = zapcore.NewSamplerWithOptions(core, tick, policy.First, policy.Thereafter, hook)
+	}
+
+	return &SampleCore{
+		Core:     core,
+		samplers: samplers,
+		state:    state,
+	}
+}
+
+// With ...
+func (c *SampleCore) With(fields []zapcore.Field) zapcore.Core {
+	samplers := make(map[zapcore.Level]zapcore.Core, len(c.samplers))
+	for lvl, s := range c.samplers {
+		samplers[lvl] = s.With(fields)
+	}
+	return &SampleCore{
+		Core:     c.Core.With(fields),
+		samplers: samplers,
+		state:    c.state,
+	}
+}
+
+// Check ...
+func (c *SampleCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if _, ok := c.state.limiters[entry.Level]; ok {
+		if c.Core.Enabled(entry.Level) {
+			return checked.AddCore(entry, c)
+		}
+		return checked
+	}
+	return c.sampledCheck(entry, checked)
+}
+
+// Write 只有配置了限流的级别会走到这里
+func (c *SampleCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if limiter, ok := c.state.limiters[entry.Level]; ok && !limiter.allow(entry) {
+		c.state.dropped(&c.state.rateLimited)
+		return nil
+	}
+
+	// 重新Check一次，让内层各个输出按自己的级别过滤
+	if ce := c.sampledCheck(entry, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *SampleCore) sampledCheck(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s, ok := c.samplers[entry.Level]; ok {
+		return s.Check(entry, checked)
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// Suppressed 返回尚未汇总输出的被丢弃条数
+func (c *SampleCore) Suppressed() (sampled, rateLimited int64) {
+	return atomic.LoadInt64(&c.state.sampled), atomic.LoadInt64(&c.state.rateLimited)
+}
+
+// Sync 先输出未汇总的被丢弃条数再刷新内层输出
+func (c *SampleCore) Sync() error {
+	c.state.summary(time.Now())
+	return c.Core.Sync()
+}
+
+// Close 停止汇总协程并输出剩余的被丢弃条数，With产生的core共用同一个协程，关闭任意一个即可
+func (c *SampleCore) Close() error {
+	c.state.close()
+	return nil
+}
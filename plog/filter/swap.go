@@ -0,0 +1,201 @@
+package filter
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// swapTarget 一次替换的内层core，With派生的core按指针判断是否需要重新派生。
+// active 为已经Check还没有写完的日志数，替换后等待其归零才能关闭原core的输出
+type swapTarget struct {
+	core    zapcore.Core
+	active  int64
+	retired int32
+	once    sync.Once
+	drained chan struct{}
+}
+
+func newSwapTarget(core zapcore.Core) *swapTarget {
+	return &swapTarget{core: core, drained: make(chan struct{})}
+}
+
+// release 写完一条日志，替换后最后一条写完时通知等待方
+func (t *swapTarget) release() {
+	if atomic.AddInt64(&t.active, -1) == 0 && atomic.LoadInt32(&t.retired) == 1 {
+		t.once.Do(func() { close(t.drained) })
+	}
+}
+
+// retire 替换后不再接受新的写入
+func (t *swapTarget) retire() {
+	atomic.StoreInt32(&t.retired, 1)
+	if atomic.LoadInt64(&t.active) == 0 {
+		t.once.Do(func() { close(t.drained) })
+	}
+}
+
+type swapCached struct {
+	target *swapTarget
+	core   zapcore.Core
+}
+
+// SwapCore 内层core可以在运行时整体替换，With派生的core同样生效，用于重新加载配置。
+// 可以与打日志并发替换
+type SwapCore struct {
+	target *atomic.Value // *swapTarget，With派生的core共用
+	fields []zapcore.Field
+	cache  *atomic.Value // *swapCached
+}
+
+// NewSwapCore ...
+func NewSwapCore(core zapcore.Core) *SwapCore {
+	target := &atomic.Value{}
+	target.Store(newSwapTarget(core))
+	return &SwapCore{
+		target: target,
+		cache:  &atomic.Value{},
+	}
+}
+
+// Swap 替换内层core，返回原来的core。已经开始写入原core的日志仍会写入原core，
+// 需要关闭原core的输出时使用 Replace
+func (c *SwapCore) Swap(core zapcore.Core) zapcore.Core {
+	return c.swap(core).core
+}
+
+// Replace 替换内层core，并等待已经开始写入原core的日志写完，最多等待timeout。
+// 返回原来的core，以及是否在超时前写完，之后可以关闭原core的输出
+func (c *SwapCore) Replace(core zapcore.Core, timeout time.Duration) (zapcore.Core, bool) {
+	old := c.swap(core)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-old.drained:
+		return old.core, true
+	case <-timer.C:
+		return old.core, false
+	}
+}
+
+func (c *SwapCore) swap(core zapcore.Core) *swapTarget {
+	old := c.target.Load().(*swapTarget)
+	c.target.Store(newSwapTarget(core))
+	old.retire()
+	return old
+}
+
+// acquire 返回当前的内层core并计入正在写入，与替换并发时使用替换后的core
+func (c *SwapCore) acquire() *swapTarget {
+	for {
+		target := c.target.Load().(*swapTarget)
+		atomic.AddInt64(&target.active, 1)
+		if atomic.LoadInt32(&target.retired) == 0 {
+			return target
+		}
+		target.release()
+	}
+}
+
+// Unwrap 返回当前的内层core，带有With的字段
+func (c *SwapCore) Unwrap() zapcore.Core {
+	return c.unwrap(c.target.Load().(*swapTarget))
+}
+
+func (c *SwapCore) unwrap(target *swapTarget) zapcore.Core {
+	if len(c.fields) == 0 {
+		return target.core
+	}
+	if cached, ok := c.cache.Load().(*swapCached); ok && cached.target == target {
+		return cached.core
+	}
+	core := target.core.With(c.fields)
+	c.cache.Store(&swapCached{target: target, core: core})
+	return core
+}
+
+// Enabled ...
+func (c *SwapCore) Enabled(lvl zapcore.Level) bool {
+	return c.Unwrap().Enabled(lvl)
+}
+
+// With 字段在第一次使用或替换之后派生到当前的内层core上
+func (c *SwapCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &SwapCore{
+		target: c.target,
+		fields: merged,
+		cache:  &atomic.Value{},
+	}
+}
+
+// Check 内层会写入时加入 swapWriter，从Check到Write完成都计入正在写入
+func (c *SwapCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	target := c.acquire()
+	ce := c.unwrap(target).Check(entry, nil)
+	if ce == nil {
+		target.release()
+		return checked
+	}
+	w := swapWriterPool.Get().(*swapWriter)
+	w.target, w.ce = target, ce
+	return checked.AddCore(entry, w)
+}
+
+// Write ...
+func (c *SwapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	target := c.acquire()
+	defer target.release()
+	return c.unwrap(target).Write(entry, fields)
+}
+
+// Sync ...
+func (c *SwapCore) Sync() error {
+	return c.Unwrap().Sync()
+}
+
+var swapWriterPool = sync.Pool{
+	New: func() interface{} {
+		return &swapWriter{}
+	},
+}
+
+// swapWriter 一次Check的结果，Write写入内层Check的结果后放回
+type swapWriter struct {
+	target *swapTarget
+	ce     *zapcore.CheckedEntry
+}
+
+// Enabled ...
+func (w *swapWriter) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With ...
+func (w *swapWriter) With([]zapcore.Field) zapcore.Core {
+	return w
+}
+
+// Check ...
+func (w *swapWriter) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, w)
+}
+
+// Write ...
+func (w *swapWriter) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	target, ce := w.target, w.ce
+	w.target, w.ce = nil, nil
+	swapWriterPool.Put(w)
+
+	defer target.release()
+	return checkedEntry{ce: ce}.Write(entry, fields)
+}
+
+// Sync ...
+func (w *swapWriter) Sync() error {
+	return nil
+}
@@ -0,0 +1,138 @@
+package filter
+
+import (
+	"encoding/base64"
+	"expvar"
+	"fmt"
+	"unicode/utf8"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const truncatedMarker = "...(truncated, original size:%d)"
+
+// OversizedEntries 消息和字符串字段总长度超过上限的日志条数，通过expvar导出
+var OversizedEntries = expvar.NewInt("plog_oversized_entries")
+
+// StringMapper 由 zap.Object 字段的值实现，返回对其中每个字符串应用f后的值，
+// 脱敏和截断通过它处理 plog.Err 等对象中的字符串
+type StringMapper interface {
+	MapStrings(f func(string) string) zapcore.ObjectMarshaler
+}
+
+// TruncateCore 截断超长的消息以及string、[]byte字段和 StringMapper 中的字符串，截断处带上原始长度
+type TruncateCore struct {
+	zapcore.Core
+	maxLen    int
+	oversized *expvar.Int
+}
+
+// NewTruncateCore maxLen<=0 时不截断。oversized不为nil时统计超长的日志条数
+func NewTruncateCore(core zapcore.Core, maxLen int, oversized *expvar.Int) zapcore.Core {
+	if maxLen <= 0 {
+		return core
+	}
+	return &TruncateCore{
+		Core:      core,
+		maxLen:    maxLen,
+		oversized: oversized,
+	}
+}
+
+// With ...
+func (c *TruncateCore) With(fields []zapcore.Field) zapcore.Core {
+	return &TruncateCore{
+		Core:      c.Core.With(c.truncateFields(fields)),
+		maxLen:    c.maxLen,
+		oversized: c.oversized,
+	}
+}
+
+// Check ...
+func (c *TruncateCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checkWrapped(c, c.Core, entry, checked)
+}
+
+// Write ...
+func (c *TruncateCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.writeTo(c.Core, entry, fields)
+}
+
+func (c *TruncateCore) writeTo(next entryWriter, entry zapcore.Entry, fields []zapcore.Field) error {
+	if c.oversized != nil {
+		size := len(entry.Message)
+		for i := range fields {
+			size += fieldSize(&fields[i])
+		}
+		if size > c.maxLen {
+			c.oversized.Add(1)
+		}
+	}
+
+	entry.Message = c.truncate(entry.Message)
+	return next.Write(entry, c.truncateFields(fields))
+}
+
+// truncateFields 没有需要截断的字段时直接返回原切片
+func (c *TruncateCore) truncateFields(fields []zapcore.Field) []zapcore.Field {
+	var ret []zapcore.Field
+	for i := range fields {
+		var mapper StringMapper
+		if fields[i].Type == zapcore.ObjectMarshalerType {
+			mapper, _ = fields[i].Interface.(StringMapper)
+		}
+		if mapper == nil && fieldSize(&fields[i]) <= c.maxLen {
+			continue
+		}
+		if ret == nil {
+			ret = make([]zapcore.Field, len(fields))
+			copy(ret, fields)
+		}
+
+		switch fields[i].Type {
+		case zapcore.ObjectMarshalerType:
+			// 对象中字符串的长度在输出时才知道，逐个截断
+			ret[i].Interface = mapper.MapStrings(c.truncate)
+		case zapcore.StringType:
+			ret[i].String = c.truncate(fields[i].String)
+		case zapcore.ByteStringType:
+			ret[i].Interface = []byte(c.truncate(string(fields[i].Interface.([]byte))))
+		case zapcore.BinaryType:
+			// 与编码器一样按base64输出，截断后转为字符串字段才能带上标记
+			data := fields[i].Interface.([]byte)
+			ret[i] = zapcore.Field{
+				Key:    fields[i].Key,
+				Type:   zapcore.StringType,
+				String: base64.StdEncoding.EncodeToString(data[:c.maxLen*3/4]) + fmt.Sprintf(truncatedMarker, len(data)),
+			}
+		}
+	}
+	if ret == nil {
+		return fields
+	}
+	return ret
+}
+
+func (c *TruncateCore) truncate(s string) string {
+	if len(s) <= c.maxLen {
+		return s
+	}
+	// 不截断在utf8字符中间
+	end := c.maxLen
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + fmt.Sprintf(truncatedMarker, len(s))
+}
+
+func fieldSize(field *zapcore.Field) int {
+	switch field.Type {
+	case zapcore.StringType:
+		return len(field.String)
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		if data, ok := field.Interface.([]byte); ok {
+			return len(data)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package plog
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+)
+
+// SetLevel 运行时修改默认实例的日志级别，未单独设置级别的模块同样生效
+func SetLevel(lvl zapcore.Level) {
+	std.SetLevel(lvl)
+}
+
+// GetLevel 返回默认实例的日志级别
+func GetLevel() zapcore.Level {
+	return std.GetLevel()
+}
+
+// SetModuleLevel 运行时设置默认实例中模块的独立级别，对已经创建的 Named 日志同样生效
+func SetModuleLevel(module string, lvl zapcore.Level) {
+	std.SetModuleLevel(module, lvl)
+}
+
+// ResetModuleLevel 取消默认实例中模块的独立级别，恢复为沿用全局级别
+func ResetModuleLevel(module string) {
+	std.ResetModuleLevel(module)
+}
+
+// GetModuleLevel 返回默认实例中模块当前生效的级别
+func GetModuleLevel(module string) zapcore.Level {
+	return std.GetModuleLevel(module)
+}
+
+// SetModuleLevel 运行时设置该实例中模块的独立级别，模块名为完整的 Named 名字
+func (l *Logger) SetModuleLevel(module string, lvl zapcore.Level) {
+	l.modules.get(module).SetLevel(lvl)
+}
+
+// ResetModuleLevel 取消该实例中模块的独立级别
+func (l *Logger) ResetModuleLevel(module string) {
+	l.modules.get(module).Reset()
+}
+
+// GetModuleLevel 返回该实例中模块当前生效的级别，未单独设置时为实例的级别
+func (l *Logger) GetModuleLevel(module string) zapcore.Level {
+	if lvl, ok := l.modules.get(module).Level(); ok {
+		return lvl
+	}
+	return l.GetLevel()
+}
+
+// Named 返回模块的子Logger，日志中会带上模块名，级别可以通过
+// util.ModuleLevel 或 SetModuleLevel 单独设置，如 plog.Named("predis")
+func Named(module string) *Logger {
+	return std.Named(module)
+}
+
+// Named 返回子模块的Logger，模块名为 父模块名.module
+func (l *Logger) Named(module string) *Logger {
+	fullName := module
+	if l.name != "" {
+		fullName = l.name + "." + module
+	}
+	return l.namedLogger(fullName, module)
+}
+
+// namedLogger fullName用于查找模块级别，name交给zap拼接到父logger的名字后面
+func (l *Logger) namedLogger(fullName, name string) *Logger {
+	if l.zl == nil {
+		return &Logger{name: fullName, level: l.level, modules: l.modules}
+	}
+
+	ml := l.modules.get(fullName)
+	zl := l.logger().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return filter.NewModuleCore(core, ml)
+	}))
+	logger := l.derive(zl.Named(name), nil)
+	logger.name = fullName
+	return logger
+}
+
+// moduleLevels 实例的模块级别，派生的Logger共用
+type moduleLevels struct {
+	mu     sync.Mutex
+	levels map[string]*filter.ModuleLevel
+}
+
+func newModuleLevels() *moduleLevels {
+	return &moduleLevels{levels: map[string]*filter.ModuleLevel{}}
+}
+
+// get 返回模块的级别，没有时创建。m为nil时返回不保存的级别
+func (m *moduleLevels) get(module string) *filter.ModuleLevel {
+	if m == nil {
+		return &filter.ModuleLevel{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ml, ok := m.levels[module]
+	if !ok {
+		ml = &filter.ModuleLevel{}
+		m.levels[module] = ml
+	}
+	return ml
+}
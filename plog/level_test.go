@@ -0,0 +1,46 @@
+package plog
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNamedModuleLevel(t *testing.T) {
+	logs := NewTestLogger(t)
+	SetLevel(zapcore.InfoLevel)
+
+	redis := Named("test_redis")
+	bw := Named("test_bw")
+	SetModuleLevel("test_redis", zapcore.WarnLevel)
+	SetModuleLevel("test_bw", zapcore.DebugLevel)
+	defer ResetModuleLevel("test_redis")
+	defer ResetModuleLevel("test_bw")
+
+	redis.Info("redis info")
+	redis.Warn("redis warn")
+	bw.Debug("bw debug")
+	bw.Named("pool").Debug("pool debug")
+	Debug("global debug")
+	Info("global info")
+
+	var msgs []string
+	for _, e := range logs.AllUntimed() {
+		msgs = append(msgs, e.LoggerName+":"+e.Message)
+	}
+	want := []string{"test_redis:redis warn", "test_bw:bw debug", "test_bw.pool:pool debug", ":global info"}
+	if len(msgs) != len(want) {
+		t.Fatalf("logs:%v", msgs)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Fatalf("logs:%v", msgs)
+		}
+	}
+
+	ResetModuleLevel("test_redis")
+	redis.Info("redis info")
+	if logs.Len() != len(want)+1 {
+		t.Fatalf("module level not reset")
+	}
+}
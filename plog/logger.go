@@ -0,0 +1,303 @@
+package plog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/mode"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// Logger 日志对象，与包级别的函数一样会自动加上 GoID 字段，可以并发使用。
+// 通过 New 创建独立输出的实例，或通过 Ctx、Named 等函数从默认实例派生
+type Logger struct {
+	zl      *zap.Logger     // 用于级别判断，不带fields
+	fields  []zapcore.Field // Ctx 中的字段，第一次输出时才附加，级别未开启的日志不需要派生core
+	once    sync.Once
+	out     *zap.Logger // zl附加fields后用于输出，通过 logger 获取
+	sugar   *zap.SugaredLogger
+	name    string           // Named 模块名
+	level   zap.AtomicLevel  // 实例的全局级别，派生的Logger共用
+	modules *moduleLevels    // 实例的模块级别，派生的Logger共用
+	closer  io.Closer        // 实例打开的日志文件，派生的Logger共用
+	options *util.Options    // 创建实例的配置，派生的Logger共用
+	swap    *filter.SwapCore // 级别判断之下的输出，重新加载配置时替换
+}
+
+// New 按options创建独立的Logger，与默认实例互不影响。
+// 同一进程内的多个实例需要通过 util.LogName 使用不同的日志文件
+func New(opts ...util.Option) (*Logger, error) {
+	config := util.DefaultLogOptions
+
+	// 自定义配置
+	for _, opt := range opts {
+		opt(&config)
+	}
+	// 实例在配置的规则之上使用自己的脱敏规则，重新加载配置时只修改该实例
+	if redactor := util.GetRedactor(&config); redactor != nil {
+		util.Redactor(filter.NewChildRedactor(redactor))(&config)
+	}
+
+	level, zl, closers, err := mode.LogInit(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := newLogger(zl.WithOptions(zap.AddCallerSkip(1)))
+	logger.level = level
+	for module, lvl := range util.GetModuleLevels(&config) {
+		logger.SetModuleLevel(module, lvl)
+	}
+	logger.closer = &outputCloser{closer: closers}
+	logger.options = &config
+	logger.swap = mode.SwapCoreOf(zl.Core())
+	return logger, nil
+}
+
+func newLogger(zl *zap.Logger) *Logger {
+	return &Logger{zl: zl, modules: newModuleLevels()}
+}
+
+// derive 派生的Logger沿用模块名和级别，fields在第一次输出时附加到zl上，调用方不能再修改
+func (l *Logger) derive(zl *zap.Logger, fields []zapcore.Field) *Logger {
+	return &Logger{
+		zl:      zl,
+		fields:  fields,
+		name:    l.name,
+		level:   l.level,
+		modules: l.modules,
+		closer:  l.closer,
+		options: l.options,
+		swap:    l.swap,
+	}
+}
+
+// Options 返回创建实例的配置，通过 util.GetRedactor 等获取，用于按实例的配置处理日志，
+// 如 otelplog 对span事件做相同的脱敏和截断。不是通过 New 创建的实例返回默认配置
+func (l *Logger) Options() util.Options {
+	if l.options == nil {
+		return util.DefaultLogOptions
+	}
+	return *l.options
+}
+
+// logger 返回附加了fields的zap.Logger，第一次调用时创建
+func (l *Logger) logger() *zap.Logger {
+	l.once.Do(l.init)
+	return l.out
+}
+
+// sugared 返回 logger 对应的SugaredLogger
+func (l *Logger) sugared() *zap.SugaredLogger {
+	l.once.Do(l.init)
+	return l.sugar
+}
+
+func (l *Logger) init() {
+	l.out = l.zl
+	if l.out != nil && len(l.fields) > 0 {
+		l.out = l.out.With(l.fields...)
+	}
+	l.sugar = newSugar(l.out)
+}
+
+// With 返回附加了fields的子Logger
+func (l *Logger) With(fields ...zapcore.Field) *Logger {
+	zl := l.logger()
+	if zl != nil && len(fields) > 0 {
+		zl = zl.With(fields...)
+	}
+	return l.derive(zl, nil)
+}
+
+// Ctx 返回带有ctx中字段的子Logger，并应用请求级缓冲和注册的ContextHook。
+// ctx中的字段在第一次输出时才附加，ctx中没有需要附加的内容时返回l本身
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	zl := l.zl
+	if len(l.fields) > 0 {
+		zl = l.logger()
+	}
+	wrapped := contextLogger(ctx, zl)
+	fields := ContextFields(ctx)
+	if wrapped == zl && len(fields) == 0 {
+		return l
+	}
+	return l.derive(wrapped, fields)
+}
+
+// SetLevel 运行时修改该实例的级别，未单独设置级别的模块同样生效
+func (l *Logger) SetLevel(lvl zapcore.Level) {
+	if l.level == (zap.AtomicLevel{}) {
+		return
+	}
+	l.level.SetLevel(lvl)
+}
+
+// GetLevel 返回该实例的级别
+func (l *Logger) GetLevel() zapcore.Level {
+	if l.level == (zap.AtomicLevel{}) {
+		return zapcore.DebugLevel
+	}
+	return l.level.Level()
+}
+
+// Sync 将缓冲的日志写入输出，之后可以继续打日志
+func (l *Logger) Sync() error {
+	if l.zl == nil {
+		return nil
+	}
+	return l.zl.Sync()
+}
+
+// Close 刷新后关闭实例打开的日志文件，之后不能再打日志，用于进程退出前。
+// 通过options传入的网络输出和告警由创建方负责关闭
+func (l *Logger) Close() error {
+	if l.zl == nil {
+		return nil
+	}
+	err := l.zl.Sync()
+	if l.closer != nil {
+		err = multierr.Append(err, l.closer.Close())
+	}
+	return err
+}
+
+// WithOptions 返回应用了opt的zap.Logger，调用位置按直接使用zap.Logger计算
+func (l *Logger) WithOptions(opt ...zap.Option) *zap.Logger {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!")
+		return nil
+	}
+	return l.logger().WithOptions(append([]zap.Option{zap.AddCallerSkip(-1)}, opt...)...)
+}
+
+// Debug logs a message at DebugLevel. The message includes any fields passed
+// at the log site, as well as any fields accumulated on the logger.
+func (l *Logger) Debug(msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!level:debug,msg:", msg)
+		return
+	}
+	if !l.zl.Core().Enabled(zapcore.DebugLevel) {
+		return
+	}
+	writeFields(l.logger().Check(zapcore.DebugLevel, msg), fields)
+}
+
+// Info logs a message at InfoLevel. The message includes any fields passed
+// at the log site, as well as any fields accumulated on the logger.
+func (l *Logger) Info(msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!level:info,msg:", msg)
+		return
+	}
+	if !l.zl.Core().Enabled(zapcore.InfoLevel) {
+		return
+	}
+	writeFields(l.logger().Check(zapcore.InfoLevel, msg), fields)
+}
+
+// Warn logs a message at WarnLevel. The message includes any fields passed
+// at the log site, as well as any fields accumulated on the logger.
+func (l *Logger) Warn(msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!level:warn,msg:", msg)
+		return
+	}
+	if !l.zl.Core().Enabled(zapcore.WarnLevel) {
+		return
+	}
+	writeFields(l.logger().Check(zapcore.WarnLevel, msg), fields)
+}
+
+// Error logs a message at ErrorLevel. The message includes any fields passed
+// at the log site, as well as any fields accumulated on the logger.
+func (l *Logger) Error(msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!level:error,msg:", msg)
+		return
+	}
+	if !l.zl.Core().Enabled(zapcore.ErrorLevel) {
+		return
+	}
+	writeFields(l.logger().Check(zapcore.ErrorLevel, msg), fields)
+}
+
+// DPanic logs a message at DPanicLevel. The message includes any fields passed
+// at the log site, as well as any fields accumulated on the logger.
+func (l *Logger) DPanic(msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!level:DPanic,msg:", msg)
+		return
+	}
+	writeFields(l.logger().Check(zapcore.DPanicLevel, msg), fields)
+}
+
+// Panic logs a message at PanicLevel, then panics.
+func (l *Logger) Panic(msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!level:panic,msg:", msg)
+		return
+	}
+	writeFields(l.logger().Check(zapcore.PanicLevel, msg), fields)
+}
+
+// Fatal logs a message at FatalLevel, then calls os.Exit(1).
+func (l *Logger) Fatal(msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Println("logger not init!!!level:fatal,msg:", msg)
+		return
+	}
+	writeFields(l.logger().Check(zapcore.FatalLevel, msg), fields)
+}
+
+// Enabled 该级别的日志是否会输出，可以在构造字段开销较大时先判断
+func (l *Logger) Enabled(lvl zapcore.Level) bool {
+	return l.zl != nil && l.zl.Core().Enabled(lvl)
+}
+
+// Log 按指定级别输出，级别由调用方的配置决定时使用
+func (l *Logger) Log(lvl zapcore.Level, msg string, fields ...zapcore.Field) {
+	if l.zl == nil {
+		fmt.Printf("logger not init!!!level:%s,msg:%s\n", lvl, msg)
+		return
+	}
+	if lvl < zapcore.DPanicLevel && !l.zl.Core().Enabled(lvl) {
+		return
+	}
+	writeFields(l.logger().Check(lvl, msg), fields)
+}
+
+// 复用输出时的字段切片。fields复制后再交给core，调用方的可变参数切片不会逃逸，
+// 级别未开启时调用不分配内存
+var fieldsPool = sync.Pool{
+	New: func() interface{} {
+		fields := make([]zapcore.Field, 0, 16)
+		return &fields
+	},
+}
+
+// writeFields ce为nil时不输出。Check需要由日志函数直接调用，caller才能跳过正确的层数
+func writeFields(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	if ce == nil {
+		return
+	}
+	p := fieldsPool.Get().(*[]zapcore.Field)
+	copied := append((*p)[:0], fields...)
+
+	ce.Write(copied...)
+
+	// 清掉引用后放回，下游不会在Write之后持有字段切片
+	for i := range copied {
+		copied[i] = zapcore.Field{}
+	}
+	*p = copied[:0]
+	fieldsPool.Put(p)
+}
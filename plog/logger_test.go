@@ -0,0 +1,76 @@
+package plog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+func TestNew(t *testing.T) {
+	logger, err := New(util.LogDir(t.TempDir()), util.LogName("plog_new_test"), util.ModuleLevel("newdb", zapcore.DebugLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	logger.SetLevel(zapcore.ErrorLevel)
+	if logger.GetLevel() != zapcore.ErrorLevel || GetLevel() == zapcore.ErrorLevel {
+		t.Fatalf("instance level:%s default level:%s", logger.GetLevel(), GetLevel())
+	}
+	if named := logger.Named("sub"); named.GetLevel() != zapcore.ErrorLevel || named.Enabled(zapcore.WarnLevel) {
+		t.Fatalf("named level:%s", named.GetLevel())
+	}
+	if opts := logger.Named("newdb").Options(); util.GetModuleLevels(&opts)["newdb"] != zapcore.DebugLevel {
+		t.Fatalf("options:%v", util.GetModuleLevels(&opts))
+	}
+	// 模块级别只属于该实例
+	if !logger.Named("newdb").Enabled(zapcore.DebugLevel) || GetModuleLevel("newdb") != GetLevel() {
+		t.Fatalf("instance module level:%s default module level:%s", logger.GetModuleLevel("newdb"), GetModuleLevel("newdb"))
+	}
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	mark := fmt.Sprintf("new_%d", time.Now().UnixNano())
+	logger.Error(mark)
+	logger.Warn(mark + "_warn")
+	Error(mark + "_default")
+	_ = logger.Sync()
+	_ = Sync()
+
+	config := logger.Options()
+	data, err := ioutil.ReadFile(util.GetLogFilePath(&config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), mark) || strings.Contains(string(data), mark+"_warn") ||
+		strings.Contains(string(data), mark+"_default") {
+		t.Fatalf("log content:%s", data)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	org := Default()
+	logs := NewTestLogger(t)
+	Info("to observer")
+
+	logger, err := New(util.LogDir(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	SetDefault(logger)
+	Info("to new default")
+	SetDefault(org)
+
+	if logs.Len() != 1 || Default() != org {
+		t.Fatalf("entries:%v", logs.AllUntimed())
+	}
+}
@@ -0,0 +1,92 @@
+package mode
+
+import (
+	zaplogfmt "github.com/jsternberg/zap-logfmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// disabledStacktrace 高于所有级别，表示不输出调用栈
+const disabledStacktrace = zapcore.FatalLevel + 1
+
+// newEncoder 按配置生成编码器，defaultEncoding 为未配置编码格式时使用的格式
+func newEncoder(base zapcore.EncoderConfig, defaultEncoding string, config *util.Options) (zapcore.Encoder, error) {
+	encConf := newEncoderConfig(base, config)
+
+	encoding := util.GetEncoding(config)
+	if encoding == "" {
+		encoding = defaultEncoding
+	}
+
+	var newEnc func(zapcore.EncoderConfig) zapcore.Encoder
+	switch encoding {
+	case util.EncodingJSON:
+		newEnc = zapcore.NewJSONEncoder
+	case util.EncodingConsole:
+		newEnc = zapcore.NewConsoleEncoder
+	case util.EncodingLogfmt:
+		newEnc = zaplogfmt.NewEncoder
+	default:
+		return nil, errors.Errorf("unknown log encoding:%s", encoding)
+	}
+	return newZeroTimeEncoder(encConf, newEnc), nil
+}
+
+// newEncoderConfig 在各模式默认配置的基础上应用时间格式、字段key和调用位置格式
+func newEncoderConfig(encConf zapcore.EncoderConfig, config *util.Options) zapcore.EncoderConfig {
+	switch layout := util.GetTimeFormat(config); layout {
+	case util.TimeFormatEpochMillis:
+		encConf.EncodeTime = zapcore.EpochMillisTimeEncoder
+	default:
+		encConf.EncodeTime = zapcore.TimeEncoderOfLayout(layout)
+	}
+
+	keys := util.GetFieldKeys(config)
+	if keys.Time != "" {
+		encConf.TimeKey = keys.Time
+	}
+	if keys.Level != "" {
+		encConf.LevelKey = keys.Level
+	}
+	if keys.Name != "" {
+		encConf.NameKey = keys.Name
+	}
+	if keys.Caller != "" {
+		encConf.CallerKey = keys.Caller
+	}
+	if keys.Message != "" {
+		encConf.MessageKey = keys.Message
+	}
+	if keys.Stacktrace != "" {
+		encConf.StacktraceKey = keys.Stacktrace
+	}
+
+	if util.GetFullCaller(config) {
+		encConf.EncodeCaller = zapcore.FullCallerEncoder
+	}
+	return encConf
+}
+
+// newSinkEncoder 网络等输出使用的编码器，默认json，与线上日志文件格式一致
+func newSinkEncoder(config *util.Options) (zapcore.Encoder, error) {
+	return newEncoder(prodEncoderConfig(), util.EncodingJSON, config)
+}
+
+// prodEncoderConfig 线上日志的默认编码配置
+func prodEncoderConfig() zapcore.EncoderConfig {
+	encConf := zap.NewProductionEncoderConfig()
+	encConf.TimeKey = reserveKeyTimeStamp
+	return encConf
+}
+
+// stacktraceOption defaultLevel 为未配置时输出调用栈的级别
+func stacktraceOption(config *util.Options, defaultLevel zapcore.Level) zap.Option {
+	lvl, ok := util.GetStacktraceLevel(config)
+	if !ok {
+		lvl = defaultLevel
+	}
+	return zap.AddStacktrace(lvl)
+}
@@ -1,10 +1,14 @@
 package mode
 
 import (
+	"io"
 	"runtime"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
+	"github.com/pan-jf/go-utils/plog/filter"
 	"github.com/pan-jf/go-utils/plog/util"
 )
 
@@ -14,29 +18,127 @@ const (
 )
 
 type logInitializer interface {
-	logInit(*util.Options) (zap.AtomicLevel, *zap.Logger, error)
+	logInit(*util.Options) (zap.AtomicLevel, *zap.Logger, Closers, error)
 }
 
-// LogInit 初始化日志
-func LogInit(config *util.Options) (zap.AtomicLevel, *zap.Logger, error) {
-	var (
-		zapInit logInitializer
-		level   zap.AtomicLevel
-		logger  *zap.Logger
-		err     error
-	)
+// Closers 日志初始化时打开的需要关闭的输出，如日志文件。
+// 通过options传入的网络输出和告警由创建方负责关闭
+type Closers []io.Closer
 
+// Close 关闭所有输出
+func (c Closers) Close() error {
+	var err error
+	for _, closer := range c {
+		err = multierr.Append(err, closer.Close())
+	}
+	return err
+}
+
+// syncHook 先刷新所有输出再执行next，避免Fatal退出时丢失缓冲中的日志
+type syncHook struct {
+	syncer zapcore.Core
+	next   zapcore.CheckWriteHook
+}
+
+// NewSyncHook 用于 zap.WithFatalHook，刷新core后执行next
+func NewSyncHook(core zapcore.Core, next zapcore.CheckWriteHook) zapcore.CheckWriteHook {
+	return &syncHook{syncer: core, next: next}
+}
+
+// OnWrite ...
+func (h *syncHook) OnWrite(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	_ = h.syncer.Sync()
+	h.next.OnWrite(ce, fields)
+}
+
+// wrapOutputs 包在所有输出组合成的core外面，处理需要修改消息和字段的逻辑，每条日志只处理一次。
+// 各输出的级别判断在内层Check中生效
+func wrapOutputs(core zapcore.Core, config *util.Options) zapcore.Core {
+	if util.GetGoID(config) {
+		core = filter.NewGoIDCore(core)
+	}
+	// 先脱敏再截断，避免截断后残缺的敏感信息匹配不上
+	core = filter.NewTruncateCore(core, util.GetMaxMsgSize(config), filter.OversizedEntries)
+	return filter.NewRedactCore(core, util.GetRedactor(config))
+}
+
+// withSinks 与配置的网络等输出及告警组合，输出与线上日志文件使用相同的编码
+func withSinks(core zapcore.Core, config *util.Options) (zapcore.Core, error) {
+	sinks := util.GetSinks(config)
+	alerts := util.GetAlerts(config)
+	if len(sinks) == 0 && len(alerts) == 0 {
+		return core, nil
+	}
+
+	cores := []zapcore.Core{core}
+	if len(sinks) > 0 {
+		encoder, err := newSinkEncoder(config)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range sinks {
+			cores = append(cores, s.Sink.Core(encoder.Clone(), s.MinLevel))
+		}
+	}
+	for _, d := range alerts {
+		cores = append(cores, d.Core())
+	}
+	return zapcore.NewTee(cores...), nil
+}
+
+// newInitializer 按运行环境选择初始化方式
+func newInitializer() logInitializer {
 	if runtime.GOOS == "linux" {
 		// 线上使用。不输出到控制台，保存到文件里
-		zapInit = &prodLogInitializer{}
-	} else {
-		//调试使用。不保存日志，在控制台查看
-		zapInit = &localLogInitializer{}
+		return &prodLogInitializer{}
+	}
+	//调试使用。不保存日志，在控制台查看
+	return &localLogInitializer{}
+}
+
+// LogInit 初始化日志，返回的Closers用于关闭日志文件。
+// 级别判断之下的输出包在 filter.SwapCore 中，重新加载配置时用 NewOutputCore 的结果替换
+func LogInit(config *util.Options) (zap.AtomicLevel, *zap.Logger, Closers, error) {
+	level, logger, closers, err := newInitializer().logInit(config)
+	if err != nil {
+		return level, logger, closers, err
+	}
+
+	// 全局级别在最外层判断，Named 模块可以替换为自己的级别
+	logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		core, closers = newSampleCore(wrapOutputs(core, config), config, closers)
+		return filter.NewLevelCore(filter.NewSwapCore(core), level)
+	}))
+	logger = logger.WithOptions(zap.WithFatalHook(NewSyncHook(logger.Core(), zapcore.WriteThenFatal)))
+
+	return level, logger, closers, nil
+}
+
+// NewOutputCore 按config创建级别判断之下的输出，与 LogInit 中 filter.SwapCore 的内层一致。
+// 调用位置、调用栈等zap.Logger上的配置沿用初始化时的设置
+func NewOutputCore(config *util.Options) (zapcore.Core, Closers, error) {
+	_, logger, closers, err := newInitializer().logInit(config)
+	if err != nil {
+		return nil, nil, err
 	}
+	core, closers := newSampleCore(wrapOutputs(logger.Core(), config), config, closers)
+	return core, closers, nil
+}
 
-	if level, logger, err = zapInit.logInit(config); err != nil {
-		return level, logger, err
+// newSampleCore 创建采样core，需要关闭时放在closers最前面，在关闭文件之前输出剩余的汇总
+func newSampleCore(core zapcore.Core, config *util.Options, closers Closers) (zapcore.Core, Closers) {
+	core = filter.NewSampleCore(core, util.GetSampleConfig(config))
+	if closer, ok := core.(io.Closer); ok {
+		closers = append(Closers{closer}, closers...)
 	}
+	return core, closers
+}
 
-	return level, logger, nil
+// SwapCoreOf 返回 LogInit 创建的core中的 filter.SwapCore，不存在时返回nil
+func SwapCoreOf(core zapcore.Core) *filter.SwapCore {
+	if lc, ok := core.(*filter.LevelCore); ok {
+		core = lc.Unwrap()
+	}
+	swap, _ := core.(*filter.SwapCore)
+	return swap
 }
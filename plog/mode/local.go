@@ -1,6 +1,8 @@
 package mode
 
 import (
+	"os"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -10,23 +12,40 @@ import (
 type localLogInitializer struct {
 }
 
-func (mLog *localLogInitializer) logInit(config *util.Options) (zap.AtomicLevel, *zap.Logger, error) {
+func (mLog *localLogInitializer) logInit(config *util.Options) (zap.AtomicLevel, *zap.Logger, Closers, error) {
 	var (
-		zapConfig zap.Config
-		zapLevel  zap.AtomicLevel
-		logger    *zap.Logger
-		err       error
+		zapLevel zap.AtomicLevel
+		logger   *zap.Logger
 	)
 
-	zapConfig = zap.NewDevelopmentConfig()
-
-	zapConfig.DisableStacktrace = true
-	zapConfig.EncoderConfig.TimeKey = reserveKeyTimeStamp
-	zapConfig.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05")
-	zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-
-	logger, err = zapConfig.Build()
-	zapLevel = zapConfig.Level
+	// 内层core保持Debug，全局级别由 LogInit 在外层判断
+	zapLevel = zap.NewAtomicLevelAt(zap.DebugLevel)
+
+	encConf := zap.NewDevelopmentEncoderConfig()
+	encConf.TimeKey = reserveKeyTimeStamp
+	// 颜色只在console格式下使用
+	if encoding := util.GetEncoding(config); encoding == "" || encoding == util.EncodingConsole {
+		encConf.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+
+	encoder, err := newEncoder(encConf, util.EncodingConsole, config)
+	if err != nil {
+		return zapLevel, logger, nil, err
+	}
+
+	stderr := zapcore.Lock(os.Stderr)
+	core, err := withSinks(zapcore.NewCore(encoder, stderr, zapcore.DebugLevel), config)
+	if err != nil {
+		return zapLevel, logger, nil, err
+	}
+
+	logger = zap.New(core,
+		zap.Development(),
+		zap.ErrorOutput(stderr),
+		zap.AddCaller(),
+		stacktraceOption(config, disabledStacktrace),
+	)
 
-	return zapLevel, logger, err
+	// 输出到控制台，没有需要关闭的文件
+	return zapLevel, logger, nil, nil
 }
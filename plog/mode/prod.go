@@ -1,8 +1,6 @@
 package mode
 
 import (
-	"time"
-
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
@@ -13,31 +11,60 @@ import (
 type prodLogInitializer struct {
 }
 
-func epochFullTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-	enc.AppendString(t.Format("2006-01-02 15:04:05"))
-}
-
-func (prod *prodLogInitializer) logInit(config *util.Options) (zap.AtomicLevel, *zap.Logger, error) {
+func (prod *prodLogInitializer) logInit(config *util.Options) (zap.AtomicLevel, *zap.Logger, Closers, error) {
 	var (
 		lLevel  zap.AtomicLevel
 		lZapLog *zap.Logger
 	)
 
+	lLevel = zap.NewAtomicLevelAt(zap.DebugLevel)
+
 	// Initialize Zap.
-	encConf := zap.NewProductionEncoderConfig()
-	encConf.TimeKey = reserveKeyTimeStamp
-	encConf.EncodeTime = epochFullTimeEncoder
-	encoder := zapcore.NewJSONEncoder(encConf)
+	encoder, err := newEncoder(prodEncoderConfig(), util.EncodingJSON, config)
+	if err != nil {
+		return lLevel, lZapLog, nil, err
+	}
 
-	lLevel = zap.NewAtomicLevelAt(zap.DebugLevel)
+	core, closers, err := newFileCores(encoder, config)
+	if err != nil {
+		return lLevel, lZapLog, nil, err
+	}
+
+	if core, err = withSinks(core, config); err != nil {
+		_ = closers.Close()
+		return lLevel, lZapLog, nil, err
+	}
+	lZapLog = zap.New(core, zap.AddCaller(), stacktraceOption(config, zapcore.DPanicLevel))
+
+	return lLevel, lZapLog, closers, nil
+}
 
-	core, err := async.NewAsyncFileCore(lLevel, encoder, util.GetLogFilePath(config))
+// newFileCores 主日志文件写入所有级别，额外的输出文件各自按级别过滤，组合成一个core。
+// 全局级别由 LogInit 在外层统一判断
+func newFileCores(encoder zapcore.Encoder, config *util.Options) (zapcore.Core, Closers, error) {
+	policy := util.GetRotatePolicy(config)
 
+	core, err := async.NewAsyncFileCoreWithPolicy(zapcore.DebugLevel, encoder, util.GetLogFilePath(config), policy)
 	if err != nil {
-		return lLevel, lZapLog, err
+		return nil, nil, err
+	}
+	closers := Closers{core}
+
+	outputs := util.GetFileOutputs(config)
+	if len(outputs) == 0 {
+		return core, closers, nil
 	}
 
-	lZapLog = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.DPanicLevel))
+	cores := []zapcore.Core{core}
+	for _, output := range outputs {
+		outCore, err := async.NewAsyncFileCoreWithPolicy(output.MinLevel, encoder.Clone(), util.GetOutputFilePath(config, output), policy)
+		if err != nil {
+			_ = closers.Close()
+			return nil, nil, err
+		}
+		closers = append(closers, outCore)
+		cores = append(cores, outCore)
+	}
 
-	return lLevel, lZapLog, nil
+	return zapcore.NewTee(cores...), closers, nil
 }
@@ -0,0 +1,188 @@
+package mode
+
+import (
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// zeroTimeEncoder 时间为零值的日志不输出时间字段，slog.Record 没有时间时按slog的约定忽略。
+// zap的编码器总会输出时间，所以另外维护一个不带时间的编码器，With添加的字段两边都写一份
+type zeroTimeEncoder struct {
+	zapcore.Encoder
+	noTime zapcore.Encoder
+}
+
+// newZeroTimeEncoder newEnc 用于按配置创建同一种编码器
+func newZeroTimeEncoder(encConf zapcore.EncoderConfig, newEnc func(zapcore.EncoderConfig) zapcore.Encoder) zapcore.Encoder {
+	if encConf.TimeKey == "" {
+		return newEnc(encConf)
+	}
+	noTime := encConf
+	noTime.TimeKey = ""
+	return &zeroTimeEncoder{Encoder: newEnc(encConf), noTime: newEnc(noTime)}
+}
+
+// Clone ...
+func (e *zeroTimeEncoder) Clone() zapcore.Encoder {
+	return &zeroTimeEncoder{Encoder: e.Encoder.Clone(), noTime: e.noTime.Clone()}
+}
+
+// EncodeEntry ...
+func (e *zeroTimeEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	if entry.Time.IsZero() {
+		return e.noTime.EncodeEntry(entry, fields)
+	}
+	return e.Encoder.EncodeEntry(entry, fields)
+}
+
+// AddArray ...
+func (e *zeroTimeEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	_ = e.noTime.AddArray(key, marshaler)
+	return e.Encoder.AddArray(key, marshaler)
+}
+
+// AddObject ...
+func (e *zeroTimeEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	_ = e.noTime.AddObject(key, marshaler)
+	return e.Encoder.AddObject(key, marshaler)
+}
+
+// AddBinary ...
+func (e *zeroTimeEncoder) AddBinary(key string, value []byte) {
+	e.noTime.AddBinary(key, value)
+	e.Encoder.AddBinary(key, value)
+}
+
+// AddByteString ...
+func (e *zeroTimeEncoder) AddByteString(key string, value []byte) {
+	e.noTime.AddByteString(key, value)
+	e.Encoder.AddByteString(key, value)
+}
+
+// AddBool ...
+func (e *zeroTimeEncoder) AddBool(key string, value bool) {
+	e.noTime.AddBool(key, value)
+	e.Encoder.AddBool(key, value)
+}
+
+// AddComplex128 ...
+func (e *zeroTimeEncoder) AddComplex128(key string, value complex128) {
+	e.noTime.AddComplex128(key, value)
+	e.Encoder.AddComplex128(key, value)
+}
+
+// AddComplex64 ...
+func (e *zeroTimeEncoder) AddComplex64(key string, value complex64) {
+	e.noTime.AddComplex64(key, value)
+	e.Encoder.AddComplex64(key, value)
+}
+
+// AddDuration ...
+func (e *zeroTimeEncoder) AddDuration(key string, value time.Duration) {
+	e.noTime.AddDuration(key, value)
+	e.Encoder.AddDuration(key, value)
+}
+
+// AddFloat64 ...
+func (e *zeroTimeEncoder) AddFloat64(key string, value float64) {
+	e.noTime.AddFloat64(key, value)
+	e.Encoder.AddFloat64(key, value)
+}
+
+// AddFloat32 ...
+func (e *zeroTimeEncoder) AddFloat32(key string, value float32) {
+	e.noTime.AddFloat32(key, value)
+	e.Encoder.AddFloat32(key, value)
+}
+
+// AddInt ...
+func (e *zeroTimeEncoder) AddInt(key string, value int) {
+	e.noTime.AddInt(key, value)
+	e.Encoder.AddInt(key, value)
+}
+
+// AddInt64 ...
+func (e *zeroTimeEncoder) AddInt64(key string, value int64) {
+	e.noTime.AddInt64(key, value)
+	e.Encoder.AddInt64(key, value)
+}
+
+// AddInt32 ...
+func (e *zeroTimeEncoder) AddInt32(key string, value int32) {
+	e.noTime.AddInt32(key, value)
+	e.Encoder.AddInt32(key, value)
+}
+
+// AddInt16 ...
+func (e *zeroTimeEncoder) AddInt16(key string, value int16) {
+	e.noTime.AddInt16(key, value)
+	e.Encoder.AddInt16(key, value)
+}
+
+// AddInt8 ...
+func (e *zeroTimeEncoder) AddInt8(key string, value int8) {
+	e.noTime.AddInt8(key, value)
+	e.Encoder.AddInt8(key, value)
+}
+
+// AddString ...
+func (e *zeroTimeEncoder) AddString(key, value string) {
+	e.noTime.AddString(key, value)
+	e.Encoder.AddString(key, value)
+}
+
+// AddTime ...
+func (e *zeroTimeEncoder) AddTime(key string, value time.Time) {
+	e.noTime.AddTime(key, value)
+	e.Encoder.AddTime(key, value)
+}
+
+// AddUint ...
+func (e *zeroTimeEncoder) AddUint(key string, value uint) {
+	e.noTime.AddUint(key, value)
+	e.Encoder.AddUint(key, value)
+}
+
+// AddUint64 ...
+func (e *zeroTimeEncoder) AddUint64(key string, value uint64) {
+	e.noTime.AddUint64(key, value)
+	e.Encoder.AddUint64(key, value)
+}
+
+// AddUint32 ...
+func (e *zeroTimeEncoder) AddUint32(key string, value uint32) {
+	e.noTime.AddUint32(key, value)
+	e.Encoder.AddUint32(key, value)
+}
+
+// AddUint16 ...
+func (e *zeroTimeEncoder) AddUint16(key string, value uint16) {
+	e.noTime.AddUint16(key, value)
+	e.Encoder.AddUint16(key, value)
+}
+
+// AddUint8 ...
+func (e *zeroTimeEncoder) AddUint8(key string, value uint8) {
+	e.noTime.AddUint8(key, value)
+	e.Encoder.AddUint8(key, value)
+}
+
+// AddUintptr ...
+func (e *zeroTimeEncoder) AddUintptr(key string, value uintptr) {
+	e.noTime.AddUintptr(key, value)
+	e.Encoder.AddUintptr(key, value)
+}
+
+// AddReflected ...
+func (e *zeroTimeEncoder) AddReflected(key string, value interface{}) error {
+	_ = e.noTime.AddReflected(key, value)
+	return e.Encoder.AddReflected(key, value)
+}
+
+// OpenNamespace ...
+func (e *zeroTimeEncoder) OpenNamespace(key string) {
+	e.noTime.OpenNamespace(key)
+	e.Encoder.OpenNamespace(key)
+}
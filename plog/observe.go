@@ -0,0 +1,43 @@
+package plog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+)
+
+// TestingT *testing.T 和 *testing.B 都满足该接口
+type TestingT interface {
+	Cleanup(func())
+}
+
+// Observe 将全局日志替换为内存中的core，返回记录到的日志和恢复原日志的函数。
+// 记录所有级别的日志，可以通过 SetLevel 调整
+func Observe() (*observer.ObservedLogs, func()) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	logger := newLogger(zap.New(filter.NewLevelCore(filter.NewGoIDCore(core), level), zap.AddCaller(), zap.AddCallerSkip(1)))
+	logger.level = level
+
+	org := std
+	SetDefault(logger)
+	return logs, func() {
+		SetDefault(org)
+	}
+}
+
+// NewTestLogger 单元测试中使用，用法
+//
+//	logs := plog.NewTestLogger(t)
+//	...
+//	if logs.FilterMessage("xxx").Len() != 1 { t.Fatal() }
+//
+// 测试结束时自动恢复原来的全局日志
+func NewTestLogger(t TestingT) *observer.ObservedLogs {
+	logs, restore := Observe()
+	t.Cleanup(restore)
+	return logs
+}
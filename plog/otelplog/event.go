@@ -0,0 +1,131 @@
+package otelplog
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog"
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+// 事件中日志本身信息的属性名
+const (
+	severityKey = "log.severity"
+	loggerKey   = "log.logger"
+	callerKey   = "code.caller"
+)
+
+// spanCore 将满足级别的日志额外记录为span事件，不影响原有输出
+type spanCore struct {
+	zapcore.Core
+	event zapcore.Core // 脱敏、截断后写入eventCore
+	level zapcore.LevelEnabler
+}
+
+// newSpanCore span事件在plog的输出之外，按logger的配置单独脱敏和截断
+func newSpanCore(core zapcore.Core, span trace.Span, level zapcore.LevelEnabler, logger *plog.Logger) *spanCore {
+	config := logger.Options()
+	event := filter.NewTruncateCore(&eventCore{span: span}, util.GetMaxMsgSize(&config), nil)
+	event = filter.NewRedactCore(event, util.GetRedactor(&config))
+	return &spanCore{Core: core, event: event, level: level}
+}
+
+// With ...
+func (c *spanCore) With(fields []zapcore.Field) zapcore.Core {
+	return &spanCore{
+		Core:  c.Core.With(fields),
+		event: c.event.With(fields),
+		level: c.level,
+	}
+}
+
+// Check ...
+func (c *spanCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	checked = c.Core.Check(entry, checked)
+	if c.level.Enabled(entry.Level) {
+		checked = c.event.Check(entry, checked)
+	}
+	return checked
+}
+
+// eventCore 只在span事件的Check中使用，Write记录事件
+type eventCore struct {
+	span   trace.Span
+	fields []zapcore.Field
+}
+
+// Enabled ...
+func (c *eventCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With ...
+func (c *eventCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	merged = append(merged, fields...)
+	return &eventCore{span: c.span, fields: merged}
+}
+
+// Check ...
+func (c *eventCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+// Write ...
+func (c *eventCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(enc.Fields)+3)
+	attrs = append(attrs, attribute.String(severityKey, entry.Level.String()))
+	if entry.LoggerName != "" {
+		attrs = append(attrs, attribute.String(loggerKey, entry.LoggerName))
+	}
+	if entry.Caller.Defined {
+		attrs = append(attrs, attribute.String(callerKey, entry.Caller.TrimmedPath()))
+	}
+	for key, value := range enc.Fields {
+		attrs = append(attrs, attributeOf(key, value))
+	}
+
+	c.span.AddEvent(entry.Message, trace.WithTimestamp(entry.Time), trace.WithAttributes(attrs...))
+	return nil
+}
+
+// Sync ...
+func (c *eventCore) Sync() error {
+	return nil
+}
+
+// attributeOf 基本类型直接转换，其它类型如对象、数组转为json字符串
+func attributeOf(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	}
+	if data, err := json.Marshal(value); err == nil {
+		return attribute.String(key, string(data))
+	}
+	return attribute.String(key, fmt.Sprint(value))
+}
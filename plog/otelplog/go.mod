@@ -0,0 +1,28 @@
+module github.com/pan-jf/go-utils/plog/otelplog
+
+go 1.22
+
+require (
+	github.com/pan-jf/go-utils/plog v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/sdk v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+	go.uber.org/zap v1.22.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/jsternberg/zap-logfmt v1.2.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/v2pro/plz v0.0.0-20180222231523-10fc95fad322 // indirect
+	go.opentelemetry.io/otel/metric v1.31.0 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.0.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
+
+replace github.com/pan-jf/go-utils/plog => ../
@@ -0,0 +1,107 @@
+// Package otelplog 将plog与OpenTelemetry链路关联：通过 plog.Ctx 输出的日志带上ctx中span的
+// trace_id、span_id，并可以把Error及以上级别的日志记录为span事件。
+// 依赖较新的Go版本，单独作为一个module，用法
+//
+//	otelplog.Install(otelplog.WithSpanEvents(zapcore.ErrorLevel))
+//	plog.Ctx(ctx).Error("query failed", plog.Err(err))
+package otelplog
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog"
+)
+
+// 默认字段名，与OpenTelemetry日志规范一致
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+type options struct {
+	traceIDKey string
+	spanIDKey  string
+	eventLevel zapcore.LevelEnabler // 为nil时不记录span事件
+	logger     *plog.Logger         // 按该实例的配置处理span事件，为nil时使用默认实例
+}
+
+// Option 配置项
+type Option func(*options)
+
+// WithKeys 修改输出的字段名，如使用 "TraceID"、"SpanID" 与 util.TraceID 保持一致
+func WithKeys(traceIDKey, spanIDKey string) Option {
+	return func(o *options) {
+		o.traceIDKey = traceIDKey
+		o.spanIDKey = spanIDKey
+	}
+}
+
+// WithSpanEvents 不低于lvl的日志同时记录为span事件，事件名为日志消息，字段作为事件属性，
+// 与日志输出一样做脱敏和截断
+func WithSpanEvents(lvl zapcore.LevelEnabler) Option {
+	return func(o *options) {
+		o.eventLevel = lvl
+	}
+}
+
+// WithLogger span事件按logger的脱敏规则和 util.MaxMsgSize 处理，默认使用记录日志时的默认实例
+func WithLogger(logger *plog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Install 注册到plog，对之后的 plog.Ctx、Logger.Ctx 及slog日志生效，在初始化时调用一次
+func Install(opts ...Option) {
+	plog.RegisterContextHook(Hook(opts...))
+}
+
+// Hook 返回ContextHook，ctx中没有有效的span时原样返回core
+func Hook(opts ...Option) plog.ContextHook {
+	o := options{
+		traceIDKey: TraceIDKey,
+		spanIDKey:  SpanIDKey,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(ctx context.Context, core zapcore.Core) zapcore.Core {
+		span := trace.SpanFromContext(ctx)
+		sc := span.SpanContext()
+		if !sc.IsValid() {
+			return core
+		}
+
+		core = core.With(o.fields(sc))
+		if o.eventLevel != nil && span.IsRecording() {
+			logger := o.logger
+			if logger == nil {
+				logger = plog.Default()
+			}
+			core = newSpanCore(core, span, o.eventLevel, logger)
+		}
+		return core
+	}
+}
+
+// Fields 返回ctx中span的trace_id、span_id字段，没有有效的span时返回nil
+func Fields(ctx context.Context) []zapcore.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	o := options{traceIDKey: TraceIDKey, spanIDKey: SpanIDKey}
+	return o.fields(sc)
+}
+
+func (o *options) fields(sc trace.SpanContext) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(o.traceIDKey, sc.TraceID().String()),
+		zap.String(o.spanIDKey, sc.SpanID().String()),
+	}
+}
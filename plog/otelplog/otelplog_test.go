@@ -0,0 +1,102 @@
+package otelplog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/pan-jf/go-utils/plog"
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+func TestInstall(t *testing.T) {
+	logs := plog.NewTestLogger(t)
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	Install(WithSpanEvents(zapcore.ErrorLevel))
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	logger := plog.Ctx(ctx).With(zap.String("k", "v"))
+	logger.Info("info")
+	logger.Error("failed", plog.Err(errors.New("boom")), zap.Int("n", 1))
+	span.End()
+	plog.Ctx(context.Background()).Error("no span")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("entries:%v", entries)
+	}
+	sc := span.SpanContext()
+	fields := entries[0].ContextMap()
+	if fields[TraceIDKey] != sc.TraceID().String() || fields[SpanIDKey] != sc.SpanID().String() {
+		t.Fatalf("fields:%v", fields)
+	}
+	if _, ok := entries[2].ContextMap()[TraceIDKey]; ok {
+		t.Fatalf("fields:%v", entries[2].ContextMap())
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || len(spans[0].Events) != 1 {
+		t.Fatalf("spans:%+v", spans)
+	}
+	event := spans[0].Events[0]
+	attrs := make(map[string]string)
+	for _, attr := range event.Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if event.Name != "failed" || attrs[severityKey] != "error" || attrs["k"] != "v" || attrs["n"] != "1" ||
+		attrs["error"] == "" || attrs[callerKey] == "" {
+		t.Fatalf("event:%s attrs:%v", event.Name, attrs)
+	}
+}
+
+func TestFields(t *testing.T) {
+	if Fields(context.Background()) != nil {
+		t.Fatal("expect nil")
+	}
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+	if fields := Fields(ctx); len(fields) != 2 || fields[1].String != span.SpanContext().SpanID().String() {
+		t.Fatalf("fields:%v", fields)
+	}
+}
+
+func TestSpanEventFilters(t *testing.T) {
+	logger, err := plog.New(util.LogDir(t.TempDir()), util.MaxMsgSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "op")
+
+	obs, _ := observer.New(zapcore.DebugLevel)
+	hook := Hook(WithSpanEvents(zapcore.ErrorLevel), WithLogger(logger))
+	zl := zap.New(hook(ctx, obs)).With(zap.String("token", "abc"))
+	zl.Error("a message longer than sixteen bytes", zap.String("password", "p@ss"), zap.String("note", "ok"))
+	span.End()
+
+	event := exporter.GetSpans()[0].Events[0]
+	attrs := make(map[string]string)
+	for _, attr := range event.Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["token"] != filter.Masked || attrs["password"] != filter.Masked || attrs["note"] != "ok" {
+		t.Fatalf("attrs:%v", attrs)
+	}
+	if !strings.HasPrefix(event.Name, "a message longer") || strings.Contains(event.Name, "sixteen") {
+		t.Fatalf("event:%s", event.Name)
+	}
+}
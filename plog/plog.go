@@ -3,15 +3,15 @@ package plog
 import (
 	"fmt"
 
-	"github.com/v2pro/plz/gls"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
-	"github.com/pan-jf/go-utils/plog/mode"
 	"github.com/pan-jf/go-utils/plog/util"
 )
 
 var (
+	// std 包级别函数使用的默认实例，zapLogger 与其保持一致
+	std       = &Logger{}
 	zapLogger *zap.Logger
 )
 
@@ -23,21 +23,23 @@ func init() {
 // initZapLog 根据options的设置,初始化日志系统。
 // 注意默认是测试环境模式,需要设置线上模式的需要设置TestEnv(false)
 func initZapLog(opts ...util.Option) error {
-	var err error
-	config := util.DefaultLogOptions
-
-	// 自定义配置
-	for _, opt := range opts {
-		opt(&config)
-	}
-
-	if _, zapLogger, err = mode.LogInit(&config); err != nil {
+	logger, err := New(opts...)
+	if err != nil {
 		return err
 	}
+	SetDefault(logger)
+	return nil
+}
 
-	zapLogger = zapLogger.WithOptions(zap.AddCallerSkip(1))
+// Default 返回包级别函数使用的默认实例
+func Default() *Logger {
+	return std
+}
 
-	return nil
+// SetDefault 替换包级别函数使用的默认实例，需要在初始化阶段调用，不能与打日志并发
+func SetDefault(logger *Logger) {
+	std = logger
+	zapLogger = logger.logger()
 }
 
 // Debug logs a message at DebugLevel. The message includes any fields passed
@@ -47,7 +49,10 @@ func Debug(msg string, fields ...zapcore.Field) {
 		fmt.Println("logger not init!!!level:debug,msg:", msg)
 		return
 	}
-	zapLogger.Debug(msg, addGoID(fields)...)
+	if !zapLogger.Core().Enabled(zapcore.DebugLevel) {
+		return
+	}
+	writeFields(zapLogger.Check(zapcore.DebugLevel, msg), fields)
 }
 
 // Info logs a message at InfoLevel. The message includes any fields passed
@@ -57,7 +62,10 @@ func Info(msg string, fields ...zapcore.Field) {
 		fmt.Println("logger not init!!!level:info,msg:", msg)
 		return
 	}
-	zapLogger.Info(msg, addGoID(fields)...)
+	if !zapLogger.Core().Enabled(zapcore.InfoLevel) {
+		return
+	}
+	writeFields(zapLogger.Check(zapcore.InfoLevel, msg), fields)
 }
 
 // Warn logs a message at WarnLevel. The message includes any fields passed
@@ -67,7 +75,10 @@ func Warn(msg string, fields ...zapcore.Field) {
 		fmt.Println("logger not init!!!level:warn,msg:", msg)
 		return
 	}
-	zapLogger.Warn(msg, addGoID(fields)...)
+	if !zapLogger.Core().Enabled(zapcore.WarnLevel) {
+		return
+	}
+	writeFields(zapLogger.Check(zapcore.WarnLevel, msg), fields)
 }
 
 // Error logs a message at ErrorLevel. The message includes any fields passed
@@ -77,8 +88,10 @@ func Error(msg string, fields ...zapcore.Field) {
 		fmt.Println("logger not init!!!level:error,msg:", msg)
 		return
 	}
-	zapLogger.Error(msg, addGoID(fields)...)
-	// 可能需要发通知 todo
+	if !zapLogger.Core().Enabled(zapcore.ErrorLevel) {
+		return
+	}
+	writeFields(zapLogger.Check(zapcore.ErrorLevel, msg), fields)
 }
 
 // DPanic logs a message at DPanicLevel. The message includes any fields passed
@@ -92,7 +105,7 @@ func DPanic(msg string, fields ...zapcore.Field) {
 		fmt.Println("logger not init!!!level:DPanic,msg:", msg)
 		return
 	}
-	zapLogger.DPanic(msg, addGoID(fields)...)
+	writeFields(zapLogger.Check(zapcore.DPanicLevel, msg), fields)
 }
 
 // Panic logs a message at PanicLevel. The message includes any fields passed
@@ -104,7 +117,7 @@ func Panic(msg string, fields ...zapcore.Field) {
 		fmt.Println("logger not init!!!level:panic,msg:", msg)
 		return
 	}
-	zapLogger.Panic(msg, addGoID(fields)...)
+	writeFields(zapLogger.Check(zapcore.PanicLevel, msg), fields)
 }
 
 // Fatal logs a message at FatalLevel. The message includes any fields passed
@@ -116,32 +129,23 @@ func Fatal(msg string, fields ...zapcore.Field) {
 		fmt.Println("logger not init!!!level:fatal,msg:", msg)
 		return
 	}
-	zapLogger.Fatal(msg, addGoID(fields)...)
+	writeFields(zapLogger.Check(zapcore.FatalLevel, msg), fields)
 }
 
-func addGoID(fields []zapcore.Field) []zapcore.Field {
-	var ret = make([]zapcore.Field, 0, len(fields)+1)
-	ret = append(ret, util.GoID(gls.GoID()))
-	ret = append(ret, fields...)
-	return ret
+// Sync calls the underlying Core's Sync method, flushing any buffered log
+// entries. Logging can continue after Sync.
+func Sync() error {
+	return std.Sync()
 }
 
-// Sync calls the underlying syslogCore's Sync method, flushing any buffered log
-// entries. Applications should take care to call Sync before exiting.
-func Sync() error {
+// Close 刷新并关闭默认实例的日志文件，应用退出前调用，之后不能再打日志
+func Close() error {
 	Info("logger closed")
-	if zapLogger != nil {
-		return zapLogger.Sync()
-	}
-	return nil
+	return std.Close()
 }
 
 // WithOptions clones the zapLogger, applies the supplied Options, and
 // returns the resulting AsyncWriterLogger. It's safe to use concurrently.
 func WithOptions(opt ...zap.Option) *zap.Logger {
-	if zapLogger == nil {
-		fmt.Println("logger not init!!!")
-		return nil
-	}
-	return zapLogger.WithOptions(opt...).With(util.GoID(gls.GoID()))
+	return std.WithOptions(opt...)
 }
@@ -1,14 +1,21 @@
 package plog
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/sink"
 	"github.com/pan-jf/go-utils/plog/util"
 )
 
@@ -40,24 +47,99 @@ func TestLog(t *testing.T) {
 
 }
 
-func TestMaxMsgSize(t *testing.T) {
-	_ = initZapLog(util.MaxMsgSize(3000000))
-	go func() {
-		ticker := time.NewTicker(time.Second * 1)
-		for range ticker.C {
-			Debug("a1")
-			Debug("max")
-			Info("max")
-		}
-
-	}()
-	time.Sleep(time.Second * 2)
+// initTestLog 与 initZapLog 相同，日志写在测试的临时目录，测试结束时关闭并恢复原来的默认实例。
+// 返回新默认实例的配置
+func initTestLog(t *testing.T, opts ...util.Option) (util.Options, error) {
+	opts = append([]util.Option{util.LogDir(t.TempDir())}, opts...)
+	org := Default()
+	if err := initZapLog(opts...); err != nil {
+		return util.Options{}, err
+	}
+	logger := Default()
+	t.Cleanup(func() {
+		SetDefault(org)
+		_ = logger.Close()
+	})
+	return logger.Options(), nil
+}
 
+func TestMaxMsgSize(t *testing.T) {
+	if _, err := initTestLog(t, util.MaxMsgSize(3000000)); err != nil {
+		t.Fatal(err)
+	}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; i < 3; i++ {
+		<-ticker.C
+		Debug("a1")
+		Debug("max")
+		Info("max")
+	}
 }
 
 func TestWithPid(t *testing.T) {
-	_ = initZapLog(util.AddPidToLogFile())
+	config, err := initTestLog(t, util.AddPidToLogFile())
+	if err != nil {
+		t.Fatal(err)
+	}
 	Debug("test")
+	if path := util.GetLogFilePath(&config); !strings.Contains(path, fmt.Sprint(os.Getpid())) {
+		t.Fatalf("path:%s", path)
+	}
+}
+
+func TestSplitErrorLog(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only prod mode writes files")
+	}
+	config, err := initTestLog(t, util.SplitErrorLog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mark := fmt.Sprintf("split_%d", time.Now().UnixNano())
+	Info(mark + "_info")
+	Warn(mark + "_warn")
+	_ = Sync()
+
+	data, err := ioutil.ReadFile(util.GetOutputFilePath(&config, util.GetFileOutputs(&config)[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), mark+"_info") || !strings.Contains(string(data), mark+"_warn") {
+		t.Fatalf("error log content:%s", data)
+	}
+	data, _ = ioutil.ReadFile(util.GetLogFilePath(&config))
+	if !strings.Contains(string(data), mark+"_info") || !strings.Contains(string(data), mark+"_warn") {
+		t.Fatalf("main log missing entries")
+	}
+}
+
+func TestEncoderOptions(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := initTestLog(t,
+		util.Encoding(util.EncodingLogfmt),
+		util.TimeFormat(util.TimeFormatEpochMillis),
+		util.RenameKeys(util.FieldKeys{Time: "@timestamp", Message: "message"}),
+		util.FullCaller(),
+		util.AddSink(sink.NewWriterSink(&buf), zapcore.InfoLevel),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Info("encoder", zap.String("k", "v"))
+	_ = Sync()
+
+	out := buf.String()
+	for _, want := range []string{"@timestamp=1", "message=encoder", "k=v", "/plog/plog_test.go:"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("want:%s output:%s", want, out)
+		}
+	}
+
+	if _, err := initTestLog(t, util.Encoding("xml")); err == nil {
+		t.Fatal("expect unknown encoding error")
+	}
 }
 
 func TestDurationField(t *testing.T) {
@@ -111,3 +193,71 @@ func BenchmarkWrap(b *testing.B) {
 		})
 	}
 }
+
+func TestSecret(t *testing.T) {
+	logs := NewTestLogger(t)
+	// 使用不在敏感字段名中的key，只靠Secret本身脱敏
+	Info("login", Secret("note", "abc"))
+	Infow("login", Secret("note", "abc"))
+
+	entries := logs.AllUntimed()
+	if entries[0].ContextMap()["note"] != "***" || entries[1].ContextMap()["note"] != "***" {
+		t.Fatalf("entries:%v", entries)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	org := zapLogger
+	logs, restore := Observe()
+	Warn("observed", zap.String("key", "v"))
+	restore()
+	Info("not observed")
+
+	if zapLogger != org {
+		t.Fatalf("logger not restored")
+	}
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Level != zapcore.WarnLevel || entries[0].ContextMap()["key"] != "v" {
+		t.Fatalf("entries:%v", entries)
+	}
+}
+
+// benchLogger 将全局日志替换为写入 ioutil.Discard 的json输出
+func benchLogger(b *testing.B, wrap func(zapcore.Core) zapcore.Core) {
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(ioutil.Discard),
+		zapcore.DebugLevel,
+	)
+	org := zapLogger
+	zapLogger = zap.New(wrap(core), zap.AddCaller(), zap.AddCallerSkip(1))
+	b.Cleanup(func() { zapLogger = org })
+	b.ReportAllocs()
+	b.ResetTimer()
+}
+
+// BenchmarkGoIDPrepend 原来每次调用都分配新切片加上GoID字段的方式，用于对比
+func BenchmarkGoIDPrepend(b *testing.B) {
+	benchLogger(b, func(core zapcore.Core) zapcore.Core { return core })
+	for i := 0; i < b.N; i++ {
+		fields := []zapcore.Field{zap.String("k", "v")}
+		ret := make([]zapcore.Field, 0, len(fields)+1)
+		ret = append(ret, util.GoID(filter.CurrentGoID()))
+		ret = append(ret, fields...)
+		Info("bench", ret...)
+	}
+}
+
+func BenchmarkGoIDCore(b *testing.B) {
+	benchLogger(b, filter.NewGoIDCore)
+	for i := 0; i < b.N; i++ {
+		Info("bench", zap.String("k", "v"))
+	}
+}
+
+func BenchmarkWithoutGoID(b *testing.B) {
+	benchLogger(b, func(core zapcore.Core) zapcore.Core { return core })
+	for i := 0; i < b.N; i++ {
+		Info("bench", zap.String("k", "v"))
+	}
+}
@@ -0,0 +1,42 @@
+package plog
+
+import (
+	"regexp"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+)
+
+// secret 实现fmt.Stringer，任何方式输出都只显示***
+type secret struct {
+	value interface{}
+}
+
+// String ...
+func (s secret) String() string {
+	return filter.Masked
+}
+
+// GoString ...
+func (s secret) GoString() string {
+	return filter.Masked
+}
+
+// Secret 敏感字段，无论值是什么都输出为***，如 plog.Secret("password", pwd)
+func Secret(key string, value interface{}) zapcore.Field {
+	return zap.Stringer(key, secret{value: value})
+}
+
+// RegisterSensitiveKey 向默认脱敏规则中增加敏感字段名，不区分大小写，
+// 字段名包含其中之一时整个值输出为***
+func RegisterSensitiveKey(keys ...string) {
+	filter.DefaultRedactor.AddKeys(keys...)
+}
+
+// RegisterSensitivePattern 向默认脱敏规则中增加敏感内容正则，消息和字符串字段中
+// 匹配的部分替换为***，正则有子匹配时只替换第一个子匹配
+func RegisterSensitivePattern(patterns ...*regexp.Regexp) {
+	filter.DefaultRedactor.AddPatterns(patterns...)
+}
@@ -0,0 +1,236 @@
+package plog
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/mode"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+const defaultWatchInterval = 5 * time.Second
+
+// 替换输出后等待正在进行的写入完成再关闭原来的日志文件，最多等待的时间。方便测试修改
+var reloadDrainTimeout = time.Second
+
+// outputCloser 重新加载配置替换输出后，Close 关闭新的输出
+type outputCloser struct {
+	mu     sync.Mutex
+	closer io.Closer
+}
+
+// Close ...
+func (c *outputCloser) Close() error {
+	c.mu.Lock()
+	closer := c.closer
+	c.mu.Unlock()
+	if closer == nil {
+		return nil
+	}
+	return closer.Close()
+}
+
+func (c *outputCloser) replace(closer io.Closer) io.Closer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	old := c.closer
+	c.closer = closer
+	return old
+}
+
+// ConfigWatcher 定期检查配置文件，有修改时校验并应用到Logger
+type ConfigWatcher struct {
+	logger *Logger
+	path   string
+	// options 实例的配置加上base，重新创建输出时在其基础上应用配置文件
+	options util.Options
+
+	// 配置文件之外的设置，配置项被删除时恢复
+	baseLevel   zapcore.Level
+	baseModules map[string]zapcore.Level
+	// 实例自己的脱敏规则，只设置配置文件中的规则，实例配置的规则及之后注册的规则通过parent生效
+	redactor *filter.Redactor
+
+	mu      sync.Mutex
+	applied *FileConfig
+	modTime time.Time
+	size    int64
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// WatchConfig 加载配置文件并应用到默认实例，之后每interval检查一次文件，<=0 时默认5秒。
+// 重新创建输出时以实例创建时的配置为基础，base 为在其上额外应用的options，之后再应用配置文件。
+// 首次加载失败时返回错误，之后加载失败会输出错误日志并保持原配置
+func WatchConfig(path string, interval time.Duration, base ...util.Option) (*ConfigWatcher, error) {
+	return std.WatchConfig(path, interval, base...)
+}
+
+// WatchConfig 同 WatchConfig，应用到该实例，只能用于 New 创建的实例及其派生的Logger
+func (l *Logger) WatchConfig(path string, interval time.Duration, base ...util.Option) (*ConfigWatcher, error) {
+	if l.swap == nil {
+		return nil, errors.New("plog: logger does not support reloading config")
+	}
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	options := l.Options()
+	for _, opt := range base {
+		opt(&options)
+	}
+	w := &ConfigWatcher{
+		logger:      l,
+		path:        path,
+		options:     options,
+		baseLevel:   l.GetLevel(),
+		baseModules: util.GetModuleLevels(&options),
+		applied:     &FileConfig{},
+		done:        make(chan struct{}),
+	}
+	if w.redactor = util.GetRedactor(&options); w.redactor == nil {
+		// 原来不脱敏，配置文件中有规则时重新创建输出
+		w.redactor = filter.NewRedactor(nil, nil)
+		w.applied.Redact.Disable = true
+	}
+
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.loop(interval)
+	return w, nil
+}
+
+// Stop 停止检查，已应用的配置保持不变
+func (w *ConfigWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+	})
+}
+
+func (w *ConfigWatcher) loop(interval time.Duration) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if w.modified() {
+				_ = w.Reload()
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// modified 按修改时间和大小判断文件是否有变化
+func (w *ConfigWatcher) modified() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !info.ModTime().Equal(w.modTime) || info.Size() != w.size
+}
+
+// Reload 立即加载配置文件，内容与已应用的相同时不做处理。
+// 校验通过后才会替换，失败时输出错误日志并保持原配置
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if info, err := os.Stat(w.path); err == nil {
+		w.modTime, w.size = info.ModTime(), info.Size()
+	}
+
+	if err := w.reload(); err != nil {
+		w.logger.Error("plog reload config failed", zap.String("file", w.path), Err(err))
+		return err
+	}
+	return nil
+}
+
+func (w *ConfigWatcher) reload() error {
+	config, err := LoadConfig(w.path)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(config, w.applied) {
+		return nil
+	}
+	parsed, err := config.parse()
+	if err != nil {
+		return err
+	}
+
+	// 先创建新的输出，失败时不修改任何配置
+	var (
+		core    zapcore.Core
+		closers mode.Closers
+	)
+	if config.needRebuild(w.applied) {
+		options := w.options
+		for _, opt := range parsed.options {
+			opt(&options)
+		}
+		if config.Redact.Disable {
+			util.DisableRedact()(&options)
+		} else {
+			util.Redactor(w.redactor)(&options)
+		}
+		if core, closers, err = mode.NewOutputCore(&options); err != nil {
+			return err
+		}
+	}
+
+	w.redactor.SetRules(config.Redact.Keys, parsed.patterns)
+	if core != nil {
+		// 等正在写入原输出的日志完成后再关闭，超时后仍然关闭
+		_, _ = w.logger.swap.Replace(core, reloadDrainTimeout)
+		if oc, ok := w.logger.closer.(*outputCloser); ok {
+			if old := oc.replace(closers); old != nil {
+				_ = old.Close()
+			}
+		}
+	}
+
+	if parsed.level != nil {
+		w.logger.SetLevel(*parsed.level)
+	} else {
+		w.logger.SetLevel(w.baseLevel)
+	}
+	for module := range w.applied.ModuleLevels {
+		if _, ok := parsed.moduleLevels[module]; ok {
+			continue
+		}
+		if lvl, ok := w.baseModules[module]; ok {
+			w.logger.SetModuleLevel(module, lvl)
+		} else {
+			w.logger.ResetModuleLevel(module)
+		}
+	}
+	for module, lvl := range parsed.moduleLevels {
+		w.logger.SetModuleLevel(module, lvl)
+	}
+
+	changes := config.diff(w.applied)
+	w.applied = config
+	w.logger.Info("plog config reloaded", zap.String("file", w.path), zap.Strings("changes", changes))
+	return nil
+}
@@ -0,0 +1,165 @@
+package plog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	base := []util.Option{util.LogDir(t.TempDir()), util.LogName("plog_reload_test")}
+	logger, err := New(base...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+	named := logger.Named("reloaddb")
+
+	path := filepath.Join(t.TempDir(), "plog.yaml")
+	writeConfig(t, path, `
+level: warn
+moduleLevels:
+  reloaddb: debug
+outputs:
+  - {name: reloaderr, level: error}
+redact:
+  keys: [card]
+`)
+	w, err := logger.WatchConfig(path, 20*time.Millisecond, base...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	if logger.GetLevel() != zapcore.WarnLevel || logger.Enabled(zapcore.InfoLevel) || !named.Enabled(zapcore.DebugLevel) {
+		t.Fatalf("level:%s module:%s", logger.GetLevel(), logger.GetModuleLevel("reloaddb"))
+	}
+
+	// 配置文件中的脱敏规则只属于该实例，之后注册的默认规则同样生效
+	RegisterSensitiveKey("reloadpin")
+	options := logger.Options()
+	redactor := util.GetRedactor(&options)
+	if filter.DefaultRedactor.IsSensitiveKey("card") || !redactor.IsSensitiveKey("card") || !redactor.IsSensitiveKey("reloadpin") {
+		t.Fatalf("default rules:%v", filter.DefaultRedactor.IsSensitiveKey("card"))
+	}
+
+	mark := "reload_" + time.Now().Format("150405.000000")
+	named.Error(mark+"_masked", zap.String("card", "6222"))
+
+	// 校验失败时保持原配置
+	writeConfig(t, path, "level: loud\n")
+	if err := w.Reload(); err == nil || logger.GetLevel() != zapcore.WarnLevel {
+		t.Fatalf("err:%v level:%s", err, logger.GetLevel())
+	}
+
+	// 删除的模块级别恢复为沿用全局级别
+	writeConfig(t, path, "level: info\noutputs:\n  - {name: reloaderr, level: error}\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for logger.GetLevel() != zapcore.InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("level:%s", logger.GetLevel())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if named.Enabled(zapcore.DebugLevel) || !named.Enabled(zapcore.InfoLevel) {
+		t.Fatalf("module level:%s", logger.GetModuleLevel("reloaddb"))
+	}
+	if redactor.IsSensitiveKey("card") || !redactor.IsSensitiveKey("reloadpin") {
+		t.Fatal("redact rules not reloaded")
+	}
+	named.Error(mark, zap.String("card", "6222"))
+	_ = logger.Sync()
+
+	config := util.DefaultLogOptions
+	for _, opt := range base {
+		opt(&config)
+	}
+	data, err := ioutil.ReadFile(util.GetOutputFilePath(&config, util.FileOutput{Name: "reloaderr"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 配置文件中的脱敏字段删除后不再脱敏
+	var masked, plain bool
+	for _, line := range strings.Split(string(data), "\n") {
+		masked = masked || strings.Contains(line, mark+"_masked") && strings.Contains(line, `"card":"***"`)
+		plain = plain || strings.Contains(line, mark+`"`) && strings.Contains(line, `"card":"6222"`)
+	}
+	if !masked || !plain {
+		t.Fatalf("log content:%s", data)
+	}
+}
+
+func TestWatchConfigLogDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only prod mode writes files")
+	}
+	dir := t.TempDir()
+	logger, err := New(util.LogDir(dir), util.LogName("plog_reload_dir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// 不传base时沿用实例自己的日志目录
+	path := filepath.Join(t.TempDir(), "plog.yaml")
+	writeConfig(t, path, "outputs:\n  - {name: reloaddir, level: error}\n")
+	w, err := logger.WatchConfig(path, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	logger.Error("reload dir")
+	_ = logger.Sync()
+	options := logger.Options()
+	file := util.GetOutputFilePath(&options, util.FileOutput{Name: "reloaddir"})
+	data, err := ioutil.ReadFile(file)
+	if err != nil || !strings.HasPrefix(file, dir) || !strings.Contains(string(data), "reload dir") {
+		t.Fatalf("content:%s err:%v", data, err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plog.json")
+	writeConfig(t, path, `{"level":"info","rateLimit":[{"level":"warn","limit":10,"interval":"1m"}]}`)
+	config, err := LoadConfig(path)
+	if err != nil || config.Level != "info" || config.RateLimit[0].Interval != "1m" {
+		t.Fatalf("config:%+v err:%v", config, err)
+	}
+
+	for name, content := range map[string]string{
+		"unknown.json":  `{"levle":"info"}`,
+		"unknown.yaml":  "levle: info\n",
+		"interval.yaml": "rateLimit:\n  - {level: warn, limit: 10, interval: 1x}\n",
+		"pattern.yaml":  "redact:\n  patterns: ['(']\n",
+		"output.yaml":   "outputs:\n  - {name: ../x, level: warn}\n",
+	} {
+		path := filepath.Join(dir, name)
+		writeConfig(t, path, content)
+		if _, err := LoadConfig(path); err == nil {
+			t.Fatalf("%s: expect error", name)
+		}
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); !os.IsNotExist(errors.Cause(err)) {
+		t.Fatalf("err:%v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package plog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// FlushOnSignal 收到信号时刷新默认实例的日志，默认监听SIGTERM和SIGINT，返回取消监听的函数。
+// 只刷新日志，不改变信号的处理，用于应用自己通过signal.Notify监听并处理退出的情况。
+// 注意监听后该信号不再按系统默认行为退出，应用没有自己处理时使用 ExitOnSignal
+func FlushOnSignal(sigs ...os.Signal) func() {
+	return std.FlushOnSignal(sigs...)
+}
+
+// ExitOnSignal 收到信号时刷新默认实例的日志，然后取消监听并重新向自身发送该信号，
+// 进程按系统默认行为退出。只能在plog是该信号唯一的监听方时使用，
+// 否则应用的监听会收到两次信号，这时使用 FlushOnSignal
+func ExitOnSignal(sigs ...os.Signal) func() {
+	return std.ExitOnSignal(sigs...)
+}
+
+// FlushOnSignal 收到信号时刷新该实例的日志，与包级别的 FlushOnSignal 相同
+func (l *Logger) FlushOnSignal(sigs ...os.Signal) func() {
+	return l.onSignal(sigs, false)
+}
+
+// ExitOnSignal 收到信号时刷新该实例的日志后退出，与包级别的 ExitOnSignal 相同
+func (l *Logger) ExitOnSignal(sigs ...os.Signal) func() {
+	return l.onSignal(sigs, true)
+}
+
+// onSignal reraise为false时每次收到信号都刷新，为true时刷新后重新发送信号并结束监听
+func (l *Logger) onSignal(sigs []os.Signal, reraise bool) func() {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				_ = l.Sync()
+				if reraise {
+					signal.Stop(ch)
+					raise(sig)
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// raise 向自身重新发送信号，不支持发送信号的平台直接退出
+func raise(sig os.Signal) {
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
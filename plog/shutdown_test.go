@@ -0,0 +1,125 @@
+package plog
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/pan-jf/go-utils/plog/mode"
+)
+
+type syncCountCore struct {
+	zapcore.Core
+	synced *int32
+}
+
+func (c syncCountCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c syncCountCore) Sync() error {
+	atomic.AddInt32(c.synced, 1)
+	return nil
+}
+
+func TestFatalFlush(t *testing.T) {
+	obs, _ := observer.New(zapcore.DebugLevel)
+	core := syncCountCore{Core: obs, synced: new(int32)}
+	logger := newLogger(zap.New(core, zap.WithFatalHook(mode.NewSyncHook(core, zapcore.WriteThenGoexit))))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Fatal("fatal")
+	}()
+	<-done
+
+	if atomic.LoadInt32(core.synced) != 1 {
+		t.Fatalf("synced:%d", atomic.LoadInt32(core.synced))
+	}
+}
+
+// waitSignal 等待测试自己的监听收到n次信号，之后不再收到
+func waitSignal(t *testing.T, received chan os.Signal, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatalf("received %d signals, want %d", i, n)
+		}
+	}
+	select {
+	case <-received:
+		t.Fatalf("received more than %d signals", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func waitSynced(t *testing.T, synced *int32, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(synced) < n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(synced) != n {
+		t.Fatalf("synced:%d want:%d", atomic.LoadInt32(synced), n)
+	}
+}
+
+func TestFlushOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported")
+	}
+
+	// 应用自己监听信号时只刷新，不会重复发送
+	received := make(chan os.Signal, 4)
+	signal.Notify(received, syscall.SIGHUP)
+	defer signal.Stop(received)
+
+	obs, _ := observer.New(zapcore.DebugLevel)
+	synced := new(int32)
+	stop := newLogger(zap.New(syncCountCore{Core: obs, synced: synced})).FlushOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	raise(syscall.SIGHUP)
+	waitSignal(t, received, 1)
+	waitSynced(t, synced, 1)
+
+	// 之后的信号同样刷新
+	raise(syscall.SIGHUP)
+	waitSignal(t, received, 1)
+	waitSynced(t, synced, 2)
+}
+
+func TestExitOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported")
+	}
+
+	// 测试自己监听信号，避免重新发送的信号按默认行为退出
+	received := make(chan os.Signal, 4)
+	signal.Notify(received, syscall.SIGHUP)
+	defer signal.Stop(received)
+
+	obs, _ := observer.New(zapcore.DebugLevel)
+	synced := new(int32)
+	stop := newLogger(zap.New(syncCountCore{Core: obs, synced: synced})).ExitOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	raise(syscall.SIGHUP)
+	waitSynced(t, synced, 1)
+	// 原信号和刷新后重新发送的信号
+	waitSignal(t, received, 2)
+}
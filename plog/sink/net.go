@@ -0,0 +1,210 @@
+package sink
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 一个UDP报文能携带的最大数据长度，超出的日志无法发送
+	maxDatagramSize = 65507
+	// 连接建立后写入失败的最大次数，超过后丢弃该条日志，避免一条日志一直重试
+	maxWriteAttempts = 3
+)
+
+// netWriter 带缓冲的网络写入，Write只负责入队，后台协程负责连接、发送和重连
+type netWriter struct {
+	network string
+	addr    string
+	opts    options
+
+	queue   chan []byte
+	dropped int64
+
+	mu      sync.Mutex
+	pending int           // 已入队但还没发送或丢弃的条数
+	drained chan struct{} // pending降为0时关闭，Sync等待
+	closed  bool          // Close之后不再入队
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+	conn      net.Conn
+}
+
+func newNetWriter(network, addr string, opts options) *netWriter {
+	if opts.bufferSize <= 0 {
+		opts.bufferSize = defaultBufferSize
+	}
+	w := &netWriter{
+		network: network,
+		addr:    addr,
+		opts:    opts,
+		queue:   make(chan []byte, opts.bufferSize),
+		done:    make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.loop()
+	return w
+}
+
+// Write 复制后入队，队列满时丢弃，不阻塞打日志的协程。
+// udp超过单个报文上限的日志无法发送，直接丢弃
+func (w *netWriter) Write(p []byte) (int, error) {
+	if w.datagram() && len(p) > maxDatagramSize {
+		atomic.AddInt64(&w.dropped, 1)
+		return len(p), nil
+	}
+
+	msg := make([]byte, len(p))
+	copy(msg, p)
+
+	// 在锁内判断关闭并入队，保证关闭后入队的日志不会留在队列里
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		atomic.AddInt64(&w.dropped, 1)
+		return len(p), nil
+	}
+	select {
+	case w.queue <- msg:
+		w.addPendingLocked(1)
+	default:
+		atomic.AddInt64(&w.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// datagram 是否按报文发送，每条日志是一个报文
+func (w *netWriter) datagram() bool {
+	return strings.HasPrefix(w.network, "udp") || w.network == "unixgram"
+}
+
+func (w *netWriter) addPending(delta int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.addPendingLocked(delta)
+}
+
+func (w *netWriter) addPendingLocked(delta int) {
+	if w.pending == 0 && delta > 0 {
+		w.drained = make(chan struct{})
+	}
+	w.pending += delta
+	if w.pending == 0 {
+		close(w.drained)
+	}
+}
+
+// Sync 等待队列中的日志发送完，最多等待 flushTimeout
+func (w *netWriter) Sync() error {
+	w.mu.Lock()
+	pending, drained := w.pending, w.drained
+	w.mu.Unlock()
+	if pending == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(w.opts.flushTimeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+	}
+	return nil
+}
+
+// Close 尽量发送完剩余日志后关闭连接
+func (w *netWriter) Close() error {
+	w.closeOnce.Do(func() {
+		_ = w.Sync()
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		close(w.done)
+		w.wg.Wait()
+	})
+	return nil
+}
+
+// Dropped 丢弃的日志条数
+func (w *netWriter) Dropped() int64 {
+	return atomic.LoadInt64(&w.dropped)
+}
+
+func (w *netWriter) loop() {
+	defer w.wg.Done()
+	defer func() {
+		if w.conn != nil {
+			_ = w.conn.Close()
+		}
+	}()
+
+	for {
+		select {
+		case msg := <-w.queue:
+			w.send(msg)
+		case <-w.done:
+			// 关闭时队列里剩余的直接丢弃
+			for {
+				select {
+				case <-w.queue:
+					w.addPending(-1)
+					atomic.AddInt64(&w.dropped, 1)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send 连接失败时等待后重连，直到成功或关闭。写入失败或超时时重连后重试，
+// 超过 maxWriteAttempts 次丢弃该条日志
+func (w *netWriter) send(msg []byte) {
+	defer w.addPending(-1)
+
+	for attempts := 0; ; {
+		if w.conn == nil {
+			conn, err := net.DialTimeout(w.network, w.addr, w.opts.dialTimeout)
+			if err != nil {
+				if !w.wait() {
+					atomic.AddInt64(&w.dropped, 1)
+					return
+				}
+				continue
+			}
+			w.conn = conn
+		}
+
+		if w.opts.writeTimeout > 0 {
+			_ = w.conn.SetWriteDeadline(time.Now().Add(w.opts.writeTimeout))
+		}
+		if _, err := w.conn.Write(msg); err == nil {
+			return
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+
+		attempts++
+		if attempts >= maxWriteAttempts || !w.wait() {
+			atomic.AddInt64(&w.dropped, 1)
+			return
+		}
+	}
+}
+
+// wait 等待重连间隔，期间关闭则返回false
+func (w *netWriter) wait() bool {
+	timer := time.NewTimer(w.opts.reconnectInterval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-w.done:
+		return false
+	}
+}
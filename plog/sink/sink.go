@@ -0,0 +1,145 @@
+package sink
+
+import (
+	"io"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultBufferSize        = 64 * 1024
+	defaultReconnectInterval = time.Second
+	defaultDialTimeout       = 3 * time.Second
+	defaultFlushTimeout      = 3 * time.Second
+	defaultWriteTimeout      = 3 * time.Second
+)
+
+// Sink 日志输出目标，可以与本地文件同时使用
+type Sink interface {
+	// Core 用给定的编码器和级别生成输出到该目标的core
+	Core(encoder zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core
+}
+
+type options struct {
+	bufferSize        int
+	reconnectInterval time.Duration
+	dialTimeout       time.Duration
+	flushTimeout      time.Duration
+	writeTimeout      time.Duration
+	facility          int
+	appName           string
+	hostname          string
+}
+
+var defaultOptions = options{
+	bufferSize:        defaultBufferSize,
+	reconnectInterval: defaultReconnectInterval,
+	dialTimeout:       defaultDialTimeout,
+	flushTimeout:      defaultFlushTimeout,
+	writeTimeout:      defaultWriteTimeout,
+	facility:          FacilityLocal0,
+}
+
+// Option 网络输出的配置函数
+type Option func(*options)
+
+// BufferSize 发送队列最多缓存的日志条数，队列满或连接断开时超出的日志会被丢弃。默认65536
+func BufferSize(size int) Option {
+	return func(o *options) {
+		o.bufferSize = size
+	}
+}
+
+// ReconnectInterval 连接失败后重连的间隔，默认1秒
+func ReconnectInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.reconnectInterval = interval
+	}
+}
+
+// DialTimeout 建立连接的超时时间，默认3秒
+func DialTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.dialTimeout = timeout
+	}
+}
+
+// FlushTimeout Sync时等待发送队列清空的最长时间，默认3秒
+func FlushTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.flushTimeout = timeout
+	}
+}
+
+// WriteTimeout 每次发送的超时时间，超时后断开重连，默认3秒。
+// 避免对端不读取时发送协程一直阻塞
+func WriteTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.writeTimeout = timeout
+	}
+}
+
+// Facility syslog的facility，默认 FacilityLocal0
+func Facility(facility int) Option {
+	return func(o *options) {
+		o.facility = facility
+	}
+}
+
+// AppName syslog的APP-NAME，默认为进程名
+func AppName(name string) Option {
+	return func(o *options) {
+		o.appName = name
+	}
+}
+
+// Hostname syslog的HOSTNAME，默认为本机hostname
+func Hostname(name string) Option {
+	return func(o *options) {
+		o.hostname = name
+	}
+}
+
+type writerSink struct {
+	ws zapcore.WriteSyncer
+}
+
+// NewWriterSink 输出到任意io.Writer，实现了Sync方法时会在Sync时调用
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{ws: zapcore.AddSync(w)}
+}
+
+// Core ...
+func (s *writerSink) Core(encoder zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core {
+	return zapcore.NewCore(encoder, s.ws, enabler)
+}
+
+// NetSink 通过tcp或udp按行发送编码后的日志
+type NetSink struct {
+	writer *netWriter
+}
+
+// NewNetSink network为tcp或udp，断开后自动重连，发送在后台协程中进行
+func NewNetSink(network, addr string, opts ...Option) *NetSink {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &NetSink{writer: newNetWriter(network, addr, o)}
+}
+
+// Core ...
+func (s *NetSink) Core(encoder zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core {
+	return zapcore.NewCore(encoder, s.writer, enabler)
+}
+
+// Close 停止后台发送并关闭连接，未发送的日志会尽量发完
+func (s *NetSink) Close() error {
+	return s.writer.Close()
+}
+
+// Dropped 因队列满、关闭、超过udp报文上限或多次写入失败而丢弃的日志条数
+func (s *NetSink) Dropped() int64 {
+	return s.writer.Dropped()
+}
@@ -0,0 +1,190 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zap.New(NewWriterSink(&buf).Core(newEncoder(), zapcore.InfoLevel))
+	logger.Debug("debug")
+	logger.Info("info", zap.String("k", "v"))
+
+	if strings.Contains(buf.String(), "debug") || !strings.Contains(buf.String(), `"k":"v"`) {
+		t.Fatalf("output:%s", buf.String())
+	}
+}
+
+func TestNetSinkReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	s := NewNetSink("tcp", addr, ReconnectInterval(10*time.Millisecond))
+	defer s.Close()
+	logger := zap.New(s.Core(newEncoder(), zapcore.DebugLevel))
+
+	// 服务端还没启动，日志缓存在队列里
+	logger.Info("before listen")
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger.Info("after listen")
+	_ = logger.Sync()
+
+	reader := bufio.NewReader(conn)
+	for _, want := range []string{"before listen", "after listen"} {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		line, err := reader.ReadString('\n')
+		if err != nil || !strings.Contains(line, want) {
+			t.Fatalf("line:%q err:%v", line, err)
+		}
+	}
+}
+
+func TestNetSinkWriteTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// 只接受一个连接且不读取，发送端写满缓冲后阻塞，超时后重连失败
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		_ = ln.Close()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	defer func() {
+		select {
+		case conn := <-accepted:
+			_ = conn.Close()
+		default:
+		}
+	}()
+
+	s := NewNetSink("tcp", ln.Addr().String(),
+		ReconnectInterval(10*time.Millisecond),
+		WriteTimeout(50*time.Millisecond),
+		FlushTimeout(100*time.Millisecond))
+	logger := zap.New(s.Core(newEncoder(), zapcore.DebugLevel))
+	msg := strings.Repeat("x", 1<<20)
+	for i := 0; i < 32; i++ {
+		logger.Info(msg)
+	}
+
+	start := time.Now()
+	_ = s.Close()
+	if time.Since(start) > time.Second {
+		t.Fatalf("close took %s", time.Since(start))
+	}
+	dropped := s.Dropped()
+	if dropped == 0 {
+		t.Fatal("expect dropped entries")
+	}
+
+	// 关闭后不再入队
+	logger.Info("after close")
+	if s.Dropped() != dropped+1 {
+		t.Fatalf("dropped:%d", s.Dropped())
+	}
+	start = time.Now()
+	_ = logger.Sync()
+	if time.Since(start) > 50*time.Millisecond {
+		t.Fatalf("sync took %s", time.Since(start))
+	}
+}
+
+func TestNetSinkOversizedDatagram(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s := NewNetSink("udp", conn.LocalAddr().String(), ReconnectInterval(10*time.Millisecond))
+	logger := zap.New(s.Core(newEncoder(), zapcore.DebugLevel))
+	logger.Info(strings.Repeat("x", maxDatagramSize))
+	logger.Info("small")
+	_ = logger.Sync()
+
+	buf := make([]byte, maxDatagramSize)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil || !strings.Contains(string(buf[:n]), "small") {
+		t.Fatalf("datagram:%q err:%v", buf[:n], err)
+	}
+	if s.Dropped() != 1 {
+		t.Fatalf("dropped:%d", s.Dropped())
+	}
+
+	start := time.Now()
+	_ = s.Close()
+	if time.Since(start) > time.Second {
+		t.Fatalf("close took %s", time.Since(start))
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	s := NewSyslogSink("udp", pc.LocalAddr().String(), AppName("plog test"), Hostname("host1"))
+	defer s.Close()
+	logger := zap.New(s.Core(newEncoder(), zapcore.DebugLevel))
+	logger.Warn("syslog", zap.Int("n", 1))
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// local0(16)*8 + warning(4) = 132
+	pattern := regexp.MustCompile(`^<132>1 \S+ host1 plogtest \d+ - - \{.*"msg":"syslog","n":1\}$`)
+	if !pattern.Match(buf[:n]) {
+		t.Fatalf("message:%s", buf[:n])
+	}
+}
+
+func TestSyslogFraming(t *testing.T) {
+	s := &SyslogSink{framing: true, facility: FacilityUser, hostname: "h", appName: "a", procID: "1"}
+	msg := s.format(zapcore.Entry{Level: zapcore.ErrorLevel, Time: time.Unix(0, 0).UTC()}, []byte("{}\n"))
+
+	body := "<11>1 1970-01-01T00:00:00.000000Z h a 1 - - {}"
+	if string(msg) != "46 "+body || len(body) != 46 {
+		t.Fatalf("message:%q", msg)
+	}
+}
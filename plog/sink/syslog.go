@@ -0,0 +1,202 @@
+package sink
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// syslog facility
+const (
+	FacilityUser   = 1
+	FacilityDaemon = 3
+	FacilityLocal0 = 16
+	FacilityLocal1 = 17
+	FacilityLocal2 = 18
+	FacilityLocal3 = 19
+	FacilityLocal4 = 20
+	FacilityLocal5 = 21
+	FacilityLocal6 = 22
+	FacilityLocal7 = 23
+)
+
+// syslog severity
+const (
+	severityEmergency = 0
+	severityAlert     = 1
+	severityCritical  = 2
+	severityError     = 3
+	severityWarning   = 4
+	severityInfo      = 6
+	severityDebug     = 7
+)
+
+const (
+	rfc5424Time    = "2006-01-02T15:04:05.000000Z07:00"
+	nilValue       = "-"
+	maxHostnameLen = 255
+	maxAppNameLen  = 48
+)
+
+// SyslogSink 按RFC 5424格式发送到syslog服务，MSG部分为编码后的日志。
+// tcp使用RFC 6587的octet counting分帧，udp每条日志一个数据包
+type SyslogSink struct {
+	writer   *netWriter
+	framing  bool
+	facility int
+	hostname string
+	appName  string
+	procID   string
+}
+
+// NewSyslogSink network为tcp或udp
+func NewSyslogSink(network, addr string, opts ...Option) *SyslogSink {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.hostname == "" {
+		o.hostname, _ = os.Hostname()
+	}
+	if o.appName == "" {
+		o.appName = filepath.Base(os.Args[0])
+	}
+
+	return &SyslogSink{
+		writer:   newNetWriter(network, addr, o),
+		framing:  network != "udp" && network != "udp4" && network != "udp6",
+		facility: o.facility,
+		hostname: headerValue(o.hostname, maxHostnameLen),
+		appName:  headerValue(o.appName, maxAppNameLen),
+		procID:   strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Core ...
+func (s *SyslogSink) Core(encoder zapcore.Encoder, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &syslogCore{
+		LevelEnabler: enabler,
+		encoder:      encoder,
+		sink:         s,
+	}
+}
+
+// Close 停止后台发送并关闭连接，未发送的日志会尽量发完
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
+
+// Dropped 因队列满或关闭而丢弃的日志条数
+func (s *SyslogSink) Dropped() int64 {
+	return s.writer.Dropped()
+}
+
+// format 生成一条完整的syslog消息
+func (s *SyslogSink) format(entry zapcore.Entry, msg []byte) []byte {
+	msg = bytes.TrimRight(msg, "\n")
+
+	var b bytes.Buffer
+	b.WriteByte('<')
+	b.WriteString(strconv.Itoa(s.facility*8 + severity(entry.Level)))
+	b.WriteString(">1 ")
+	b.WriteString(entry.Time.Format(rfc5424Time))
+	b.WriteByte(' ')
+	b.WriteString(s.hostname)
+	b.WriteByte(' ')
+	b.WriteString(s.appName)
+	b.WriteByte(' ')
+	b.WriteString(s.procID)
+	// MSGID 和 STRUCTURED-DATA 不使用
+	b.WriteString(" - - ")
+	b.Write(msg)
+
+	if !s.framing {
+		return b.Bytes()
+	}
+	framed := make([]byte, 0, b.Len()+8)
+	framed = strconv.AppendInt(framed, int64(b.Len()), 10)
+	framed = append(framed, ' ')
+	return append(framed, b.Bytes()...)
+}
+
+type syslogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	sink    *SyslogSink
+}
+
+// With ...
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &syslogCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      c.encoder.Clone(),
+		sink:         c.sink,
+	}
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+// Check ...
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write ...
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buffer, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode log entry")
+	}
+	_, err = c.sink.writer.Write(c.sink.format(entry, buffer.Bytes()))
+	buffer.Free()
+	return err
+}
+
+// Sync ...
+func (c *syslogCore) Sync() error {
+	return c.sink.writer.Sync()
+}
+
+func severity(lvl zapcore.Level) int {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return severityDebug
+	case zapcore.InfoLevel:
+		return severityInfo
+	case zapcore.WarnLevel:
+		return severityWarning
+	case zapcore.ErrorLevel:
+		return severityError
+	case zapcore.DPanicLevel:
+		return severityCritical
+	case zapcore.PanicLevel:
+		return severityAlert
+	case zapcore.FatalLevel:
+		return severityEmergency
+	}
+	return severityInfo
+}
+
+// headerValue RFC 5424 头部字段只能是可打印ASCII且不含空格，有长度限制，为空时用 -
+func headerValue(s string, maxLen int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < maxLen; i++ {
+		if s[i] > 32 && s[i] < 127 {
+			b = append(b, s[i])
+		}
+	}
+	if len(b) == 0 {
+		return nilValue
+	}
+	return string(b)
+}
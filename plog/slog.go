@@ -0,0 +1,202 @@
+//go:build go1.21
+// +build go1.21
+
+package plog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler 使用plog输出的 slog.Handler，与plog共用输出、编码、级别和GoID字段，
+// ctx中通过 WithContext 附加的字段也会输出
+type SlogHandler struct {
+	core zapcore.Core
+	name string
+	// fields 分组内的字段，包含打开分组的 zap.Namespace。不能用core.With添加，
+	// 否则之后写入的GoID和ctx中的字段也会落在分组里，因此在Handle时加在这些字段前面
+	fields []zapcore.Field
+	// groups 还没有字段的分组，有字段时才输出，空分组按slog的约定忽略
+	groups []string
+}
+
+// NewSlogHandler 基于当前的全局日志创建，重新初始化plog后需要重新创建
+func NewSlogHandler() *SlogHandler {
+	return std.SlogHandler()
+}
+
+// SlogHandler 基于该Logger创建 slog.Handler
+func (l *Logger) SlogHandler() *SlogHandler {
+	core := zapcore.NewNopCore()
+	if l.zl != nil {
+		core = l.logger().Core()
+	}
+	return &SlogHandler{core: core, name: l.name}
+}
+
+// SetSlogDefault 将 slog 的默认Logger替换为plog输出，标准库 log 包的输出也会经过slog写入plog
+func SetSlogDefault() {
+	slog.SetDefault(slog.New(NewSlogHandler()))
+}
+
+// Enabled ...
+func (h *SlogHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	if h.core.Enabled(slogLevel(lvl)) {
+		return true
+	}
+	buffer := contextBuffer(ctx)
+	return buffer != nil && buffer.Enabled(slogLevel(lvl))
+}
+
+// Handle record.Time为零值时按slog的约定不输出时间，由plog创建的编码器处理
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	entry := zapcore.Entry{
+		Level:      slogLevel(record.Level),
+		Time:       record.Time,
+		LoggerName: h.name,
+		Message:    record.Message,
+	}
+	core := h.core
+	if ctx != nil {
+		core = contextCore(ctx, core)
+	}
+	ce := core.Check(entry, nil)
+	if ce == nil {
+		return nil
+	}
+
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       frame.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+
+	// 复制一份，避免append修改ctx中保存的字段。ctx中的字段在分组外，放在最前面
+	fields := append([]zapcore.Field(nil), ContextFields(ctx)...)
+	fields = append(fields, h.fields...)
+	attrs := make([]zapcore.Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = appendAttr(attrs, attr)
+		return true
+	})
+	if len(attrs) > 0 {
+		for _, group := range h.groups {
+			fields = append(fields, zap.Namespace(group))
+		}
+		fields = append(fields, attrs...)
+	}
+
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs 不在分组内的字段用core.With提前编码，分组内的字段在Handle时写入
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zapcore.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = appendAttr(fields, attr)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	if len(h.fields) == 0 && len(h.groups) == 0 {
+		return &SlogHandler{core: h.core.With(fields), name: h.name}
+	}
+
+	grouped := make([]zapcore.Field, 0, len(h.fields)+len(h.groups)+len(fields))
+	grouped = append(grouped, h.fields...)
+	for _, group := range h.groups {
+		grouped = append(grouped, zap.Namespace(group))
+	}
+	return &SlogHandler{core: h.core, name: h.name, fields: append(grouped, fields...)}
+}
+
+// WithGroup ...
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, 0, len(h.groups)+1)
+	groups = append(groups, h.groups...)
+	return &SlogHandler{core: h.core, name: h.name, fields: h.fields, groups: append(groups, name)}
+}
+
+// slogLevel slog级别按区间对应到zap级别
+func slogLevel(lvl slog.Level) zapcore.Level {
+	switch {
+	case lvl < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case lvl < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case lvl < slog.LevelError:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// appendAttr 转换为zap字段，空的attr和没有字段的分组忽略
+func appendAttr(fields []zapcore.Field, attr slog.Attr) []zapcore.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	value := attr.Value
+	switch value.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, value.Time()))
+	case slog.KindGroup:
+		group := slogGroup(appendGroup(nil, value.Group()))
+		if len(group) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			return append(fields, zap.Inline(group))
+		}
+		return append(fields, zap.Object(attr.Key, group))
+	}
+
+	if err, ok := value.Any().(error); ok {
+		return append(fields, zap.NamedError(attr.Key, err))
+	}
+	return append(fields, zap.Any(attr.Key, value.Any()))
+}
+
+// slogGroup 分组中的字段作为嵌套对象输出
+type slogGroup []zapcore.Field
+
+// MarshalLogObject ...
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, field := range g {
+		field.AddTo(enc)
+	}
+	return nil
+}
+
+func appendGroup(fields []zapcore.Field, attrs []slog.Attr) []zapcore.Field {
+	for _, attr := range attrs {
+		fields = appendAttr(fields, attr)
+	}
+	return fields
+}
@@ -0,0 +1,111 @@
+//go:build go1.21
+// +build go1.21
+
+package plog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/pan-jf/go-utils/plog/sink"
+	"github.com/pan-jf/go-utils/plog/util"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logs := NewTestLogger(t)
+
+	ctx := WithContext(context.Background(), zap.String("uid", "u1"))
+	logger := slog.New(NewSlogHandler()).With("svc", "api")
+	logger.DebugContext(ctx, "debug slog", "n", 1)
+	logger.WithGroup("req").Warn("warn slog", "path", "/a")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("entries:%v", entries)
+	}
+
+	debug := entries[0]
+	fields := debug.ContextMap()
+	if debug.Level != zapcore.DebugLevel || fields["uid"] != "u1" || fields["svc"] != "api" ||
+		fields["n"] != int64(1) || fields["GoID"] == nil || !strings.HasSuffix(debug.Caller.File, "slog_test.go") {
+		t.Fatalf("entry:%+v fields:%v", debug, fields)
+	}
+
+	warn := entries[1]
+	req, _ := warn.ContextMap()["req"].(map[string]interface{})
+	if warn.Level != zapcore.WarnLevel || req["path"] != "/a" {
+		t.Fatalf("entry:%+v fields:%v", warn, warn.ContextMap())
+	}
+
+	SetLevel(zapcore.InfoLevel)
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("debug should be disabled")
+	}
+}
+
+func TestSlogHandlerGroup(t *testing.T) {
+	logs := NewTestLogger(t)
+
+	ctx := WithContext(context.Background(), zap.String("uid", "u1"))
+	logger := slog.New(NewSlogHandler()).With("svc", "api").WithGroup("req").With("path", "/a").WithGroup("q")
+	logger.InfoContext(ctx, "grouped", "id", 1)
+
+	fields := logs.AllUntimed()[0].ContextMap()
+	req, _ := fields["req"].(map[string]interface{})
+	q, _ := req["q"].(map[string]interface{})
+	if fields["GoID"] == nil || fields["uid"] != "u1" || fields["svc"] != "api" ||
+		req["path"] != "/a" || req["GoID"] != nil || req["uid"] != nil || q["id"] != int64(1) {
+		t.Fatalf("fields:%v", fields)
+	}
+}
+
+func TestSlogHandlerZeroTime(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := initTestLog(t, util.AddSink(sink.NewWriterSink(&buf), zapcore.InfoLevel)); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewSlogHandler().WithAttrs([]slog.Attr{slog.String("svc", "api")})
+	_ = handler.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "no time", 0))
+	_ = handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "with time", 0))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || strings.Contains(lines[0], `"ts"`) || !strings.Contains(lines[0], `"svc":"api"`) ||
+		!strings.Contains(lines[1], `"ts"`) || !strings.Contains(lines[1], `"svc":"api"`) {
+		t.Fatalf("output:%s", buf.String())
+	}
+}
+
+func TestSlogHandlerConformance(t *testing.T) {
+	logs := NewTestLogger(t)
+
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		return NewSlogHandler()
+	}, func(t *testing.T) map[string]any {
+		entries := logs.TakeAll()
+		if len(entries) != 1 {
+			t.Fatalf("entries:%v", entries)
+		}
+		return slogResult(entries[0])
+	})
+}
+
+// slogResult 转换为slogtest要求的格式
+func slogResult(entry observer.LoggedEntry) map[string]any {
+	result := entry.ContextMap()
+	delete(result, "GoID")
+	if !entry.Time.IsZero() {
+		result[slog.TimeKey] = entry.Time
+	}
+	result[slog.LevelKey] = entry.Level
+	result[slog.MessageKey] = entry.Message
+	return result
+}
@@ -0,0 +1,29 @@
+package plog
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedirectStdLog 将标准库 log 包的输出以Info级别写入plog，返回恢复原输出的函数。
+// 重新初始化plog后需要再次调用
+func RedirectStdLog() func() {
+	restore, err := RedirectStdLogAt(zapcore.InfoLevel)
+	if err != nil {
+		fmt.Println("redirect std log failed:", err)
+		return func() {}
+	}
+	return restore
+}
+
+// RedirectStdLogAt 与 RedirectStdLog 相同，使用指定的级别输出
+func RedirectStdLogAt(lvl zapcore.Level) (func(), error) {
+	if std.zl == nil {
+		return nil, errors.New("logger not init")
+	}
+	// zap会自己跳过log包的调用栈
+	return zap.RedirectStdLogAt(std.WithOptions(), lvl)
+}
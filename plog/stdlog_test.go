@@ -0,0 +1,23 @@
+package plog
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	logs := NewTestLogger(t)
+	restore := RedirectStdLog()
+	defer restore()
+
+	log.Print("from std log")
+
+	entries := logs.FilterMessage("from std log").All()
+	if len(entries) != 1 || entries[0].Level != zapcore.InfoLevel ||
+		!strings.HasSuffix(entries[0].Caller.File, "stdlog_test.go") {
+		t.Fatalf("entries:%v", entries)
+	}
+}
@@ -0,0 +1,192 @@
+package plog
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Debugf 使用fmt.Sprintf格式化后输出Debug日志
+func Debugf(template string, args ...interface{}) {
+	sugarLog(std, zapcore.DebugLevel, template, args, nil)
+}
+
+// Infof 使用fmt.Sprintf格式化后输出Info日志
+func Infof(template string, args ...interface{}) {
+	sugarLog(std, zapcore.InfoLevel, template, args, nil)
+}
+
+// Warnf 使用fmt.Sprintf格式化后输出Warn日志
+func Warnf(template string, args ...interface{}) {
+	sugarLog(std, zapcore.WarnLevel, template, args, nil)
+}
+
+// Errorf 使用fmt.Sprintf格式化后输出Error日志
+func Errorf(template string, args ...interface{}) {
+	sugarLog(std, zapcore.ErrorLevel, template, args, nil)
+}
+
+// DPanicf 使用fmt.Sprintf格式化后输出DPanic日志
+func DPanicf(template string, args ...interface{}) {
+	sugarLog(std, zapcore.DPanicLevel, template, args, nil)
+}
+
+// Panicf 使用fmt.Sprintf格式化后输出Panic日志，然后panic
+func Panicf(template string, args ...interface{}) {
+	sugarLog(std, zapcore.PanicLevel, template, args, nil)
+}
+
+// Fatalf 使用fmt.Sprintf格式化后输出Fatal日志，然后调用os.Exit(1)
+func Fatalf(template string, args ...interface{}) {
+	sugarLog(std, zapcore.FatalLevel, template, args, nil)
+}
+
+// Debugw 输出Debug日志，keysAndValues为交替出现的键值对，如
+//
+//	plog.Debugw("msg", "uid", uid, "cost", cost)
+func Debugw(msg string, keysAndValues ...interface{}) {
+	sugarLog(std, zapcore.DebugLevel, msg, nil, keysAndValues)
+}
+
+// Infow 输出Info日志，keysAndValues为交替出现的键值对
+func Infow(msg string, keysAndValues ...interface{}) {
+	sugarLog(std, zapcore.InfoLevel, msg, nil, keysAndValues)
+}
+
+// Warnw 输出Warn日志，keysAndValues为交替出现的键值对
+func Warnw(msg string, keysAndValues ...interface{}) {
+	sugarLog(std, zapcore.WarnLevel, msg, nil, keysAndValues)
+}
+
+// Errorw 输出Error日志，keysAndValues为交替出现的键值对
+func Errorw(msg string, keysAndValues ...interface{}) {
+	sugarLog(std, zapcore.ErrorLevel, msg, nil, keysAndValues)
+}
+
+// DPanicw 输出DPanic日志，keysAndValues为交替出现的键值对
+func DPanicw(msg string, keysAndValues ...interface{}) {
+	sugarLog(std, zapcore.DPanicLevel, msg, nil, keysAndValues)
+}
+
+// Panicw 输出Panic日志后panic，keysAndValues为交替出现的键值对
+func Panicw(msg string, keysAndValues ...interface{}) {
+	sugarLog(std, zapcore.PanicLevel, msg, nil, keysAndValues)
+}
+
+// Fatalw 输出Fatal日志后调用os.Exit(1)，keysAndValues为交替出现的键值对
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	sugarLog(std, zapcore.FatalLevel, msg, nil, keysAndValues)
+}
+
+// newSugar 比zl多跳过一层 sugarLog 的调用栈
+func newSugar(zl *zap.Logger) *zap.SugaredLogger {
+	if zl == nil {
+		return nil
+	}
+	return zl.WithOptions(zap.AddCallerSkip(1)).Sugar()
+}
+
+// sugarLog printf风格和键值对风格函数的公共实现，级别未开启时不做格式化
+func sugarLog(l *Logger, lvl zapcore.Level, template string, args []interface{}, keysAndValues []interface{}) {
+	if l.zl == nil {
+		fmt.Printf("logger not init!!!level:%s,msg:%s\n", lvl, fmt.Sprintf(template, args...))
+		return
+	}
+	if lvl < zapcore.DPanicLevel && !l.zl.Core().Enabled(lvl) {
+		return
+	}
+	s := l.sugared()
+
+	msg := template
+	if len(args) > 0 {
+		msg = fmt.Sprintf(template, args...)
+	}
+
+	switch lvl {
+	case zapcore.DebugLevel:
+		s.Debugw(msg, keysAndValues...)
+	case zapcore.InfoLevel:
+		s.Infow(msg, keysAndValues...)
+	case zapcore.WarnLevel:
+		s.Warnw(msg, keysAndValues...)
+	case zapcore.ErrorLevel:
+		s.Errorw(msg, keysAndValues...)
+	case zapcore.DPanicLevel:
+		s.DPanicw(msg, keysAndValues...)
+	case zapcore.PanicLevel:
+		s.Panicw(msg, keysAndValues...)
+	case zapcore.FatalLevel:
+		s.Fatalw(msg, keysAndValues...)
+	}
+}
+
+// Debugf 使用fmt.Sprintf格式化后输出Debug日志
+func (l *Logger) Debugf(template string, args ...interface{}) {
+	sugarLog(l, zapcore.DebugLevel, template, args, nil)
+}
+
+// Infof 使用fmt.Sprintf格式化后输出Info日志
+func (l *Logger) Infof(template string, args ...interface{}) {
+	sugarLog(l, zapcore.InfoLevel, template, args, nil)
+}
+
+// Warnf 使用fmt.Sprintf格式化后输出Warn日志
+func (l *Logger) Warnf(template string, args ...interface{}) {
+	sugarLog(l, zapcore.WarnLevel, template, args, nil)
+}
+
+// Errorf 使用fmt.Sprintf格式化后输出Error日志
+func (l *Logger) Errorf(template string, args ...interface{}) {
+	sugarLog(l, zapcore.ErrorLevel, template, args, nil)
+}
+
+// DPanicf 使用fmt.Sprintf格式化后输出DPanic日志
+func (l *Logger) DPanicf(template string, args ...interface{}) {
+	sugarLog(l, zapcore.DPanicLevel, template, args, nil)
+}
+
+// Panicf 使用fmt.Sprintf格式化后输出Panic日志，然后panic
+func (l *Logger) Panicf(template string, args ...interface{}) {
+	sugarLog(l, zapcore.PanicLevel, template, args, nil)
+}
+
+// Fatalf 使用fmt.Sprintf格式化后输出Fatal日志，然后调用os.Exit(1)
+func (l *Logger) Fatalf(template string, args ...interface{}) {
+	sugarLog(l, zapcore.FatalLevel, template, args, nil)
+}
+
+// Debugw 输出Debug日志，keysAndValues为交替出现的键值对
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	sugarLog(l, zapcore.DebugLevel, msg, nil, keysAndValues)
+}
+
+// Infow 输出Info日志，keysAndValues为交替出现的键值对
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	sugarLog(l, zapcore.InfoLevel, msg, nil, keysAndValues)
+}
+
+// Warnw 输出Warn日志，keysAndValues为交替出现的键值对
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	sugarLog(l, zapcore.WarnLevel, msg, nil, keysAndValues)
+}
+
+// Errorw 输出Error日志，keysAndValues为交替出现的键值对
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	sugarLog(l, zapcore.ErrorLevel, msg, nil, keysAndValues)
+}
+
+// DPanicw 输出DPanic日志，keysAndValues为交替出现的键值对
+func (l *Logger) DPanicw(msg string, keysAndValues ...interface{}) {
+	sugarLog(l, zapcore.DPanicLevel, msg, nil, keysAndValues)
+}
+
+// Panicw 输出Panic日志后panic，keysAndValues为交替出现的键值对
+func (l *Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	sugarLog(l, zapcore.PanicLevel, msg, nil, keysAndValues)
+}
+
+// Fatalw 输出Fatal日志后调用os.Exit(1)，keysAndValues为交替出现的键值对
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	sugarLog(l, zapcore.FatalLevel, msg, nil, keysAndValues)
+}
@@ -0,0 +1,32 @@
+package plog
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSugar(t *testing.T) {
+	logs := NewTestLogger(t)
+
+	Infof("hello %s", "world")
+	Warnw("kv", "uid", "u1", "cost", 3)
+	Ctx(context.Background()).Errorf("ctx %d", 1)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("entries:%v", entries)
+	}
+	if entries[0].Message != "hello world" || entries[2].Message != "ctx 1" {
+		t.Fatalf("messages:%v", entries)
+	}
+	fields := entries[1].ContextMap()
+	if fields["uid"] != "u1" || fields["cost"] != int64(3) || fields["GoID"] == nil {
+		t.Fatalf("fields:%v", fields)
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Caller.File, "sugar_test.go") {
+			t.Fatalf("caller:%v", e.Caller)
+		}
+	}
+}
@@ -3,13 +3,39 @@ package util
 import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/filter"
 )
 
 const (
-	logCommonKeyGoID = "GoID"
+	logCommonKeyGoID      = filter.GoIDKey
+	logCommonKeyTraceID   = "TraceID"
+	logCommonKeySpanID    = "SpanID"
+	logCommonKeyUserID    = "UserID"
+	logCommonKeyRequestID = "RequestID"
 )
 
 // GoID 协程ID
 func GoID(goID int64) zapcore.Field {
 	return zap.Int64(logCommonKeyGoID, goID)
 }
+
+// TraceID 链路追踪ID
+func TraceID(traceID string) zapcore.Field {
+	return zap.String(logCommonKeyTraceID, traceID)
+}
+
+// SpanID 链路追踪中的当前span
+func SpanID(spanID string) zapcore.Field {
+	return zap.String(logCommonKeySpanID, spanID)
+}
+
+// UserID 用户ID
+func UserID(userID string) zapcore.Field {
+	return zap.String(logCommonKeyUserID, userID)
+}
+
+// RequestID 请求ID
+func RequestID(requestID string) zapcore.Field {
+	return zap.String(logCommonKeyRequestID, requestID)
+}
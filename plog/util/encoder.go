@@ -0,0 +1,98 @@
+package util
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 日志编码格式
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+	EncodingLogfmt  = "logfmt"
+)
+
+// 时间格式，除以下特殊值外按 time.Format 的layout处理
+const (
+	TimeFormatDefault     = "2006-01-02 15:04:05"
+	TimeFormatRFC3339Nano = time.RFC3339Nano
+	TimeFormatEpochMillis = "epoch_millis"
+)
+
+// FieldKeys 日志固定字段的key，为空的保持默认
+type FieldKeys struct {
+	Time       string
+	Level      string
+	Name       string
+	Caller     string
+	Message    string
+	Stacktrace string
+}
+
+// Encoding 日志编码格式，线上默认json，本地默认带颜色的console
+func Encoding(encoding string) Option {
+	return func(o *Options) {
+		o.encoding = encoding
+	}
+}
+
+// GetEncoding 获取编码格式，未设置时为空
+func GetEncoding(opt *Options) string {
+	return opt.encoding
+}
+
+// TimeFormat 时间格式，默认为 TimeFormatDefault
+func TimeFormat(layout string) Option {
+	return func(o *Options) {
+		o.timeFormat = layout
+	}
+}
+
+// GetTimeFormat 获取时间格式
+func GetTimeFormat(opt *Options) string {
+	if opt.timeFormat == "" {
+		return TimeFormatDefault
+	}
+	return opt.timeFormat
+}
+
+// RenameKeys 修改固定字段的key，用于对齐日志平台的字段，如
+// util.RenameKeys(util.FieldKeys{Time: "@timestamp", Message: "message"})
+func RenameKeys(keys FieldKeys) Option {
+	return func(o *Options) {
+		o.fieldKeys = keys
+	}
+}
+
+// GetFieldKeys 获取修改的字段key
+func GetFieldKeys(opt *Options) FieldKeys {
+	return opt.fieldKeys
+}
+
+// FullCaller 调用位置输出完整路径，默认只输出 包名/文件名:行号
+func FullCaller() Option {
+	return func(o *Options) {
+		o.fullCaller = true
+	}
+}
+
+// GetFullCaller ...
+func GetFullCaller(opt *Options) bool {
+	return opt.fullCaller
+}
+
+// StacktraceLevel 不低于该级别的日志输出调用栈，线上默认DPanic，本地默认不输出
+func StacktraceLevel(lvl zapcore.Level) Option {
+	return func(o *Options) {
+		o.stacktraceLevel = &lvl
+	}
+}
+
+// GetStacktraceLevel 获取输出调用栈的级别，未设置时ok为false
+func GetStacktraceLevel(opt *Options) (lvl zapcore.Level, ok bool) {
+	if opt.stacktraceLevel == nil {
+		return lvl, false
+	}
+	return *opt.stacktraceLevel, true
+}
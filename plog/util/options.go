@@ -1,20 +1,65 @@
 package util
 
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/pan-jf/go-utils/plog/alert"
+	"github.com/pan-jf/go-utils/plog/async"
+	"github.com/pan-jf/go-utils/plog/filter"
+	"github.com/pan-jf/go-utils/plog/sink"
+)
+
 const (
 	linuxBaseDir       = "/data/plog"
 	defaultLogPath     = "/%s/%s.log"
 	logFileWithPidPath = "/%s/%s_%d.log"
+	logFileExt         = ".log"
 )
 
 // Options 参数配置
 type Options struct {
-	logFileHasPid bool // 设置后在保存日志时会带上pid
+	logFileHasPid bool   // 设置后在保存日志时会带上pid
+	logName       string // 日志文件名，默认为进程名
+	logDir        string // 日志根目录，默认linux为/data/plog
+	disableGoID   bool
 	maxMsgLen     int
+	rotate        async.RotatePolicy // 日志切割策略
+	fileOutputs   []FileOutput       // 按级别拆分的额外日志文件
+	moduleLevels  map[string]zapcore.Level
+	redactor      *filter.Redactor // 脱敏规则，nil表示不脱敏
+	sample        filter.SampleConfig
+	sinks         []SinkOutput // 文件之外的输出
+	alerts        []*alert.Dispatcher
+
+	// 编码相关，为空时使用各模式的默认值
+	encoding        string
+	timeFormat      string
+	fieldKeys       FieldKeys
+	fullCaller      bool
+	stacktraceLevel *zapcore.Level
+}
+
+// SinkOutput 文件之外的日志输出
+type SinkOutput struct {
+	Sink     sink.Sink
+	MinLevel zapcore.Level
+}
+
+// FileOutput 额外的日志输出文件
+type FileOutput struct {
+	// Name 文件名中的标识，如 "error" 对应 进程名.error.log
+	Name string
+	// MinLevel 写入该文件的最低日志级别
+	MinLevel zapcore.Level
 }
 
 var DefaultLogOptions = Options{
 	logFileHasPid: false,
 	maxMsgLen:     8 * 1024 * 1024, //
+	rotate:        async.DefaultRotatePolicy,
+	redactor:      filter.DefaultRedactor,
 }
 
 // Option 配置函数
@@ -27,9 +72,226 @@ func AddPidToLogFile() Option {
 	}
 }
 
-// MaxMsgSize 单条日志最大长度，默认为2048
+// LogName 日志文件名使用name，目录不变，用于同一进程内 plog.New 创建的多个实例写入不同的文件
+func LogName(name string) Option {
+	return func(o *Options) {
+		o.logName = name
+	}
+}
+
+// LogDir 日志根目录使用dir，文件仍在 dir/进程名/ 下，用于测试或没有/data权限的环境
+func LogDir(dir string) Option {
+	return func(o *Options) {
+		o.logDir = dir
+	}
+}
+
+// DisableGoID 不输出GoID字段
+func DisableGoID() Option {
+	return func(o *Options) {
+		o.disableGoID = true
+	}
+}
+
+// GetGoID 是否输出GoID字段，默认输出
+func GetGoID(opt *Options) bool {
+	return !opt.disableGoID
+}
+
+// MaxMsgSize 日志消息及单个string、[]byte字段的最大长度，超出部分会被截断并标注原始长度，
+// <=0 表示不限制。默认为8MB
 func MaxMsgSize(size int) Option {
 	return func(o *Options) {
 		o.maxMsgLen = size
 	}
 }
+
+// AddFileOutput 额外输出一个日志文件，只写入不低于minLevel的日志。
+// 文件与主日志在同一目录，文件名为 进程名.name.log，切割策略与主日志相同
+func AddFileOutput(name string, minLevel zapcore.Level) Option {
+	return func(o *Options) {
+		o.fileOutputs = append(o.fileOutputs, FileOutput{
+			Name:     name,
+			MinLevel: minLevel,
+		})
+	}
+}
+
+// SplitErrorLog Warn及以上级别的日志额外写入 进程名.error.log，方便告警采集
+func SplitErrorLog() Option {
+	return AddFileOutput("error", zapcore.WarnLevel)
+}
+
+// GetFileOutputs 获取额外的日志输出文件
+func GetFileOutputs(opt *Options) []FileOutput {
+	return opt.fileOutputs
+}
+
+// ModuleLevel 设置 plog.Named(module) 日志的独立级别，未设置的模块沿用全局级别
+func ModuleLevel(module string, lvl zapcore.Level) Option {
+	return func(o *Options) {
+		levels := make(map[string]zapcore.Level, len(o.moduleLevels)+1)
+		for k, v := range o.moduleLevels {
+			levels[k] = v
+		}
+		levels[module] = lvl
+		o.moduleLevels = levels
+	}
+}
+
+// GetModuleLevels 获取配置的模块级别
+func GetModuleLevels(opt *Options) map[string]zapcore.Level {
+	return opt.moduleLevels
+}
+
+// AddSink 增加一个文件之外的输出，只写入不低于minLevel的日志，如
+// util.AddSink(sink.NewSyslogSink("udp", "127.0.0.1:514"), zapcore.InfoLevel)
+func AddSink(s sink.Sink, minLevel zapcore.Level) Option {
+	return func(o *Options) {
+		o.sinks = append(o.sinks, SinkOutput{
+			Sink:     s,
+			MinLevel: minLevel,
+		})
+	}
+}
+
+// GetSinks 获取文件之外的输出
+func GetSinks(opt *Options) []SinkOutput {
+	return opt.sinks
+}
+
+// AddAlert 增加告警分发，达到告警级别的日志会异步发给注册的通知方式，如
+// util.AddAlert(alert.NewDispatcher(alert.Config{Notifiers: []alert.Notifier{alert.NewWebhookNotifier(url)}}))
+func AddAlert(d *alert.Dispatcher) Option {
+	return func(o *Options) {
+		o.alerts = append(o.alerts, d)
+	}
+}
+
+// GetAlerts 获取告警分发
+func GetAlerts(opt *Options) []*alert.Dispatcher {
+	return opt.alerts
+}
+
+// Redactor 使用自定义的脱敏规则，默认为 filter.DefaultRedactor
+func Redactor(redactor *filter.Redactor) Option {
+	return func(o *Options) {
+		o.redactor = redactor
+	}
+}
+
+// DisableRedact 不对日志做脱敏处理
+func DisableRedact() Option {
+	return func(o *Options) {
+		o.redactor = nil
+	}
+}
+
+// GetRedactor 获取脱敏规则
+func GetRedactor(opt *Options) *filter.Redactor {
+	return opt.redactor
+}
+
+// Sampling 对lvl级别的日志采样，同一消息每秒先输出first条，之后每thereafter条输出一条
+func Sampling(lvl zapcore.Level, first, thereafter int) Option {
+	return func(o *Options) {
+		policies := make(map[zapcore.Level]filter.SamplingPolicy, len(o.sample.Sampling)+1)
+		for k, v := range o.sample.Sampling {
+			policies[k] = v
+		}
+		policies[lvl] = filter.SamplingPolicy{Tick: time.Second, First: first, Thereafter: thereafter}
+		o.sample.Sampling = policies
+	}
+}
+
+// RateLimit 对lvl级别的日志限流，同一调用位置的同一消息每个interval内最多输出limit条
+func RateLimit(lvl zapcore.Level, limit int, interval time.Duration) Option {
+	return func(o *Options) {
+		policies := make(map[zapcore.Level]filter.RateLimitPolicy, len(o.sample.RateLimit)+1)
+		for k, v := range o.sample.RateLimit {
+			policies[k] = v
+		}
+		policies[lvl] = filter.RateLimitPolicy{Interval: interval, Limit: limit}
+		o.sample.RateLimit = policies
+	}
+}
+
+// SuppressedSummaryInterval 采样和限流丢弃条数的汇总日志输出间隔，默认10秒
+func SuppressedSummaryInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.sample.SummaryInterval = interval
+	}
+}
+
+// GetSampleConfig 获取采样和限流配置
+func GetSampleConfig(opt *Options) filter.SampleConfig {
+	return opt.sample
+}
+
+// RotateBySize 按文件大小切割日志，单位MB，<=0 表示不按大小切割。默认4GB
+func RotateBySize(maxSizeMB int) Option {
+	return func(o *Options) {
+		o.rotate.MaxSize = maxSizeMB
+	}
+}
+
+// RotateByTime 按时间切割日志，async.RotateHourly 每个整点切割，
+// async.RotateDaily 每天本地时间零点切割。可与 RotateBySize 同时使用
+func RotateByTime(interval async.RotateInterval) Option {
+	return func(o *Options) {
+		o.rotate.Interval = interval
+	}
+}
+
+// MaxBackups 最多保留的备份文件个数，0 表示不限制。默认10
+func MaxBackups(n int) Option {
+	return func(o *Options) {
+		o.rotate.MaxBackups = n
+	}
+}
+
+// MaxAge 备份文件最多保留的天数，0 表示不限制。默认7天
+func MaxAge(days int) Option {
+	return func(o *Options) {
+		o.rotate.MaxAge = days
+	}
+}
+
+// CompressBackups 使用gzip压缩切割出来的备份文件
+func CompressBackups() Option {
+	return func(o *Options) {
+		o.rotate.Compress = true
+	}
+}
+
+// MaxTotalSize 日志文件及其备份占用磁盘总量上限，单位MB，超出时从最旧的备份开始删除
+func MaxTotalSize(sizeMB int) Option {
+	return func(o *Options) {
+		o.rotate.MaxTotalSize = sizeMB
+	}
+}
+
+// OnRotate 日志切割完成后的回调，参数为备份文件路径，可用于通知日志采集程序
+func OnRotate(fn func(backup string)) Option {
+	return func(o *Options) {
+		o.rotate.OnRotate = fn
+	}
+}
+
+// ExternalRotate 由系统logrotate切割日志，文件被移走或收到SIGHUP时重新打开，
+// 内置的切割配置不再生效，如 logrotate 配置 postrotate 中执行 kill -HUP
+func ExternalRotate() Option {
+	return func(o *Options) {
+		o.rotate.External = true
+	}
+}
+
+// GetRotatePolicy 获取日志切割策略
+func GetRotatePolicy(opt *Options) async.RotatePolicy {
+	return opt.rotate
+}
+
+// GetMaxMsgSize 获取单条日志最大长度
+func GetMaxMsgSize(opt *Options) int {
+	return opt.maxMsgLen
+}
@@ -22,12 +22,24 @@ func ProcessName() string {
 func GetLogFilePath(opt *Options) string {
 	baseDir := os.TempDir() + string(os.PathSeparator) + "plog" + string(os.PathSeparator)
 
-	if runtime.GOOS == "linux" {
+	if opt.logDir != "" {
+		baseDir = opt.logDir
+	} else if runtime.GOOS == "linux" {
 		baseDir = linuxBaseDir
 	}
 
+	fileName := ProcessName()
+	if opt.logName != "" {
+		fileName = opt.logName
+	}
+
 	if opt.logFileHasPid {
-		return baseDir + fmt.Sprintf(logFileWithPidPath, ProcessName(), ProcessName(), os.Getpid())
+		return baseDir + fmt.Sprintf(logFileWithPidPath, ProcessName(), fileName, os.Getpid())
 	}
-	return baseDir + fmt.Sprintf(defaultLogPath, ProcessName(), ProcessName())
+	return baseDir + fmt.Sprintf(defaultLogPath, ProcessName(), fileName)
+}
+
+// GetOutputFilePath 额外输出文件的路径，与主日志同目录
+func GetOutputFilePath(opt *Options, output FileOutput) string {
+	return strings.TrimSuffix(GetLogFilePath(opt), logFileExt) + "." + output.Name + logFileExt
 }